@@ -0,0 +1,100 @@
+package blog
+
+import (
+	"strconv"
+
+	"github.com/go-catupiry/catu"
+	"github.com/sirupsen/logrus"
+)
+
+// BlogUserIsEditor - Check if the user is listed in blog editors
+func BlogUserIsEditor(blogID uint64, userID string) (bool, error) {
+	db := catu.GetDefaultDatabaseConnection()
+
+	var count int64
+	err := db.Model(&BlogEditorsModel{}).
+		Where("blog_id = ? AND user_id = ?", blogID, userID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// CanManageBlogPosts - Check if the authenticated user has the global permission or one blog editor role with that permission
+func CanManageBlogPosts(ctx *catu.RequestContext, permission string, blogID *uint64) bool {
+	if ctx.Can(permission) {
+		return true
+	}
+
+	if blogID == nil || *blogID == 0 || !ctx.IsAuthenticated || ctx.AuthenticatedUser == nil {
+		return false
+	}
+
+	role, err := BlogEditorFindRole(*blogID, ctx.AuthenticatedUser.GetID())
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"blogId":     *blogID,
+			"permission": permission,
+			"error":      err,
+		}).Error("CanManageBlogPosts error on check blog editor")
+		return false
+	}
+
+	return BlogEditorRoleCan(role, permission)
+}
+
+// CanManageBlogEditors - Check if the authenticated user can add, update or remove the blog editors
+func CanManageBlogEditors(ctx *catu.RequestContext, blogID uint64) bool {
+	if ctx.Can("update_blog") {
+		return true
+	}
+
+	if !ctx.IsAuthenticated || ctx.AuthenticatedUser == nil {
+		return false
+	}
+
+	role, err := BlogEditorFindRole(blogID, ctx.AuthenticatedUser.GetID())
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"blogId": blogID,
+			"error":  err,
+		}).Error("CanManageBlogEditors error on check blog editor")
+		return false
+	}
+
+	return role == BlogEditorRoleOwner
+}
+
+// parseBlogID - Parse one blog id from string, returns nil if the value is empty or invalid
+func parseBlogID(v string) *uint64 {
+	id, err := strconv.ParseUint(v, 10, 64)
+	if err != nil || id == 0 {
+		return nil
+	}
+
+	return &id
+}
+
+func sameBlogID(a, b *uint64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
+
+// getAuthenticatedUserID - Get the authenticated user id from request context, returns nil if not authenticated
+func getAuthenticatedUserID(ctx *catu.RequestContext) *uint64 {
+	if !ctx.IsAuthenticated || ctx.AuthenticatedUser == nil {
+		return nil
+	}
+
+	id, err := strconv.ParseUint(ctx.AuthenticatedUser.GetID(), 10, 64)
+	if err != nil || id == 0 {
+		return nil
+	}
+
+	return &id
+}
@@ -0,0 +1,309 @@
+package blog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/go-catupiry/catu"
+	"github.com/sirupsen/logrus"
+)
+
+// Blog audit log actions
+const (
+	BlogAuditActionCreate       = "create"
+	BlogAuditActionUpdate       = "update"
+	BlogAuditActionDelete       = "delete"
+	BlogAuditActionPublish      = "publish"
+	BlogAuditActionUnpublish    = "unpublish"
+	BlogAuditActionEditorChange = "editor-change"
+)
+
+// actor name of the changes made by the cron-job
+const BlogAuditSystemActor = "system"
+
+// BlogAuditLogModel Stores one administrative action in blogs or posts. Records are never updated or deleted
+type BlogAuditLogModel struct {
+	ID         uint64          `gorm:"primaryKey;column:id" json:"id"`
+	Action     string          `gorm:"index:blogAuditAction;column:action;type:varchar(30);not null" json:"action"`
+	ActorID    *uint64         `gorm:"index:blogAuditActor;column:actorId" json:"actorId"`
+	Actor      string          `gorm:"column:actor;type:varchar(255);not null" json:"actor"`
+	IP         string          `gorm:"column:ip;type:varchar(45)" json:"ip"`
+	TargetType string          `gorm:"index:blogAuditTarget;column:targetType;type:varchar(30);not null" json:"targetType"`
+	TargetID   uint64          `gorm:"index:blogAuditTarget;column:targetId;not null" json:"targetId"`
+	BlogID     *uint64         `gorm:"index:blogAuditBlog;column:blogId" json:"blogId"`
+	Changes    json.RawMessage `gorm:"column:changes;type:text" json:"changes"`
+	CreatedAt  time.Time       `gorm:"index:blogAuditCreatedAt;column:createdAt;type:datetime;not null" json:"createdAt"`
+}
+
+// TableName get sql table name
+func (m *BlogAuditLogModel) TableName() string {
+	return "blog_audit_log"
+}
+
+// BlogAuditActor - User or system that made the change
+type BlogAuditActor struct {
+	ID   *uint64
+	Name string
+	IP   string
+}
+
+// BlogAuditChange - Field value before and after the change
+type BlogAuditChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+func IsValidBlogAuditAction(action string) bool {
+	switch action {
+	case BlogAuditActionCreate, BlogAuditActionUpdate, BlogAuditActionDelete,
+		BlogAuditActionPublish, BlogAuditActionUnpublish, BlogAuditActionEditorChange:
+		return true
+	}
+
+	return false
+}
+
+// NewBlogAuditActorFromCtx - Actor from the request authenticated user and ip
+func NewBlogAuditActorFromCtx(ctx *catu.RequestContext) *BlogAuditActor {
+	actor := BlogAuditActor{
+		ID:   getAuthenticatedUserID(ctx),
+		Name: "anonymous",
+		IP:   ctx.RealIP(),
+	}
+
+	if actor.ID != nil {
+		actor.Name = ctx.AuthenticatedUser.GetDisplayName()
+		if actor.Name == "" {
+			actor.Name = strconv.FormatUint(*actor.ID, 10)
+		}
+	}
+
+	return &actor
+}
+
+// NewBlogAuditSystemActor - Actor of the cron-job changes
+func NewBlogAuditSystemActor() *BlogAuditActor {
+	return &BlogAuditActor{Name: BlogAuditSystemActor}
+}
+
+// AuditFields - Blog fields stored in the audit log diff, tags only if loaded
+func (r *BlogModel) AuditFields() map[string]interface{} {
+	fields := map[string]interface{}{
+		"title":            r.Title,
+		"description":      r.Description,
+		"descriptionSmall": r.DescriptionSmall,
+		"urlUniquePath":    r.URLUniquePath,
+		"showInLists":      r.ShowInLists,
+		"creatorId":        r.CreatorID,
+	}
+
+	if r.Tags != nil {
+		fields["tags"] = r.Tags
+	}
+
+	return fields
+}
+
+// AuditFields - Blog post fields stored in the audit log diff, tags only if loaded
+func (r *BlogPostModel) AuditFields() map[string]interface{} {
+	fields := map[string]interface{}{
+		"title":         r.Title,
+		"teaser":        r.Teaser,
+		"body":          r.Body,
+		"bodyFormat":    r.BodyFormat,
+		"status":        r.Status,
+		"published":     r.Published,
+		"publishedAt":   r.PublishedAt,
+		"unpublishAt":   r.UnpublishAt,
+		"highlighted":   r.Highlighted,
+		"allowComments": r.AllowComments,
+		"urlPath":       r.URLPath,
+		"blogId":        r.BlogID,
+		"inRSS":         r.InRSS,
+		"showInLists":   r.ShowInLists,
+		"creatorId":     r.CreatorID,
+	}
+
+	if r.Tags != nil {
+		fields["tags"] = r.Tags
+	}
+
+	return fields
+}
+
+// BlogAuditDiff - Changed fields between the before and after values. Use nil before for creates and nil after for deletes
+func BlogAuditDiff(before, after map[string]interface{}) map[string]*BlogAuditChange {
+	changes := map[string]*BlogAuditChange{}
+
+	if after == nil {
+		for key, value := range before {
+			changes[key] = &BlogAuditChange{From: value}
+		}
+
+		return changes
+	}
+
+	for key, value := range after {
+		var old interface{}
+		if before != nil {
+			old = before[key]
+		}
+
+		if before != nil && sameAuditValue(old, value) {
+			continue
+		}
+
+		changes[key] = &BlogAuditChange{From: old, To: value}
+	}
+
+	return changes
+}
+
+func sameAuditValue(a, b interface{}) bool {
+	aJSON, aErr := json.Marshal(a)
+	bJSON, bErr := json.Marshal(b)
+
+	return aErr == nil && bErr == nil && bytes.Equal(aJSON, bJSON)
+}
+
+// WriteBlogAuditLog - Append one audit log record. Updates without changes are not stored
+func WriteBlogAuditLog(actor *BlogAuditActor, action, targetType string, targetID uint64, blogID *uint64, changes map[string]*BlogAuditChange) error {
+	if action == BlogAuditActionUpdate && len(changes) == 0 {
+		return nil
+	}
+
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	record := BlogAuditLogModel{
+		Action:     action,
+		ActorID:    actor.ID,
+		Actor:      actor.Name,
+		IP:         actor.IP,
+		TargetType: targetType,
+		TargetID:   targetID,
+		BlogID:     blogID,
+		Changes:    changesJSON,
+	}
+
+	db := catu.GetDefaultDatabaseConnection()
+
+	return db.Create(&record).Error
+}
+
+// auditBlog - Write the blog audit log, errors are only logged
+func auditBlog(actor *BlogAuditActor, action string, blog *BlogModel, before, after map[string]interface{}) {
+	blogID := blog.ID
+
+	err := WriteBlogAuditLog(actor, action, "blog", blog.ID, &blogID, BlogAuditDiff(before, after))
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"id":     blog.ID,
+			"action": action,
+			"error":  err,
+		}).Error("auditBlog error on write audit log")
+	}
+}
+
+// auditBlogPost - Write the blog post audit log, errors are only logged
+func auditBlogPost(actor *BlogAuditActor, action string, post *BlogPostModel, before, after map[string]interface{}) {
+	err := WriteBlogAuditLog(actor, action, "blog-post", post.ID, post.BlogID, BlogAuditDiff(before, after))
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"id":     post.ID,
+			"action": action,
+			"error":  err,
+		}).Error("auditBlogPost error on write audit log")
+	}
+}
+
+// auditBlogEditor - Write the blog editor role change, use empty roles for added and removed editors
+func auditBlogEditor(actor *BlogAuditActor, blog *BlogModel, userID int64, fromRole, toRole string) {
+	change := BlogAuditChange{}
+	if fromRole != "" {
+		change.From = fromRole
+	}
+	if toRole != "" {
+		change.To = toRole
+	}
+
+	blogID := blog.ID
+	changes := map[string]*BlogAuditChange{
+		"editors." + strconv.FormatInt(userID, 10): &change,
+	}
+
+	err := WriteBlogAuditLog(actor, BlogAuditActionEditorChange, "blog", blog.ID, &blogID, changes)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"id":     blog.ID,
+			"userId": userID,
+			"error":  err,
+		}).Error("auditBlogEditor error on write audit log")
+	}
+}
+
+// BlogAuditLogQueryOpts - Audit log filters, empty filters are ignored
+type BlogAuditLogQueryOpts struct {
+	Action     string
+	ActorID    string
+	Actor      string
+	TargetType string
+	TargetID   string
+	BlogID     string
+	Since      *time.Time
+	Until      *time.Time
+	Records    *[]*BlogAuditLogModel
+	Count      *int64
+	Limit      int
+	Offset     int
+}
+
+// BlogAuditLogQueryAndCount - Find the audit log records, newest first
+func BlogAuditLogQueryAndCount(opts *BlogAuditLogQueryOpts) error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	query := db.Model(&BlogAuditLogModel{})
+
+	if opts.Action != "" {
+		query = query.Where("action = ?", opts.Action)
+	}
+	if opts.ActorID != "" {
+		query = query.Where("actorId = ?", opts.ActorID)
+	}
+	if opts.Actor != "" {
+		query = query.Where("actor = ?", opts.Actor)
+	}
+	if opts.TargetType != "" {
+		query = query.Where("targetType = ?", opts.TargetType)
+	}
+	if opts.TargetID != "" {
+		query = query.Where("targetId = ?", opts.TargetID)
+	}
+	if opts.BlogID != "" {
+		query = query.Where("blogId = ?", opts.BlogID)
+	}
+	if opts.Since != nil {
+		query = query.Where("createdAt >= ?", *opts.Since)
+	}
+	if opts.Until != nil {
+		query = query.Where("createdAt < ?", *opts.Until)
+	}
+
+	err := query.Count(opts.Count).Error
+	if err != nil {
+		return err
+	}
+
+	if opts.Limit > 0 {
+		query = query.Limit(opts.Limit).Offset(opts.Offset)
+	}
+
+	return query.
+		Order("createdAt DESC").
+		Order("id DESC").
+		Find(opts.Records).Error
+}
@@ -0,0 +1,106 @@
+package blog
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-catupiry/catu"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+)
+
+type BlogAuditLogJSONResponse struct {
+	catu.BaseListReponse
+	Records []*BlogAuditLogModel `json:"blog-audit-log"`
+}
+
+// Http blog audit log controller | struct with the read only audit log http handlers
+type BlogAuditLogController struct {
+	App catu.App
+}
+
+// Query - Find the audit log records. Filters: action, actorId, actor, targetType, targetId, blogId and since/until RFC 3339 dates
+func (ctl *BlogAuditLogController) Query(c echo.Context) error {
+	ctx := c.(*catu.RequestContext)
+
+	if !ctx.Can("access_blog_audit_log") {
+		return echo.NewHTTPError(http.StatusForbidden, "Forbidden")
+	}
+
+	opts := BlogAuditLogQueryOpts{
+		Action:     c.QueryParam("action"),
+		ActorID:    c.QueryParam("actorId"),
+		Actor:      c.QueryParam("actor"),
+		TargetType: c.QueryParam("targetType"),
+		TargetID:   c.QueryParam("targetId"),
+		BlogID:     c.QueryParam("blogId"),
+		Limit:      ctx.GetLimit(),
+		Offset:     ctx.GetOffset(),
+	}
+
+	if opts.Action != "" && !IsValidBlogAuditAction(opts.Action) {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid action")
+	}
+
+	for name, value := range map[string]string{"actorId": opts.ActorID, "targetId": opts.TargetID, "blogId": opts.BlogID} {
+		if value == "" {
+			continue
+		}
+		if _, err := strconv.ParseUint(value, 10, 64); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid "+name)
+		}
+	}
+
+	var err error
+	opts.Since, err = parseAuditLogDate(c.QueryParam("since"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid since date")
+	}
+
+	opts.Until, err = parseAuditLogDate(c.QueryParam("until"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid until date")
+	}
+
+	var count int64
+	records := []*BlogAuditLogModel{}
+	opts.Records = &records
+	opts.Count = &count
+
+	err = BlogAuditLogQueryAndCount(&opts)
+	if err != nil {
+		return errors.Wrap(err, "BlogAuditLogController.Query error on find audit log")
+	}
+
+	resp := BlogAuditLogJSONResponse{
+		Records: records,
+	}
+
+	resp.Meta.Count = count
+
+	return c.JSON(http.StatusOK, &resp)
+}
+
+func parseAuditLogDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	date, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &date, nil
+}
+
+type BlogAuditLogControllerCfg struct {
+	App catu.App
+}
+
+func NewBlogAuditLogController(cfg *BlogAuditLogControllerCfg) *BlogAuditLogController {
+	ctx := BlogAuditLogController{App: cfg.App}
+
+	return &ctx
+}
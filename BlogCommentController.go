@@ -0,0 +1,422 @@
+package blog
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-catupiry/catu"
+	"github.com/gookit/event"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type BlogCommentJSONResponse struct {
+	catu.BaseListReponse
+	Records []*BlogCommentModel `json:"blog-comment"`
+}
+
+type BlogCommentFindOneJSONResponse struct {
+	Record *BlogCommentModel `json:"blog-comment"`
+}
+
+type BlogCommentBodyRequest struct {
+	Record *BlogCommentModel `json:"blog-comment"`
+	// honeypot field, must be empty
+	Website string `json:"website"`
+	// token from the form-token endpoint, used in the min time to submit check
+	FormToken string `json:"formToken"`
+}
+
+// Http blog comment controller | struct with http handlers for blog post comments
+type BlogCommentController struct {
+	App   catu.App
+	Guard *SubmissionGuard
+}
+
+func (ctl *BlogCommentController) Query(c echo.Context) error {
+	ctx := c.(*catu.RequestContext)
+
+	post, err := findCommentsBlogPost(c)
+	if err != nil {
+		return err
+	}
+
+	canModerate := CanManageBlogPosts(ctx, "moderate_blog-comment", post.BlogID)
+
+	status := BlogCommentStatusApproved
+	if canModerate {
+		status = c.QueryParam("status")
+		if status != "" && !IsValidBlogCommentStatus(status) {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid status")
+		}
+	}
+
+	var count int64
+	records := []*BlogCommentModel{}
+	err = BlogCommentQueryAndCount(&BlogCommentQueryOpts{
+		BlogPostID: post.GetIDString(),
+		Status:     status,
+		Records:    &records,
+		Count:      &count,
+		Limit:      ctx.GetLimit(),
+		Offset:     ctx.GetOffset(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "BlogCommentController.Query error on find comments")
+	}
+
+	if !canModerate {
+		for _, r := range records {
+			r.AuthorEmail = ""
+		}
+	}
+
+	resp := BlogCommentJSONResponse{
+		Records: records,
+	}
+
+	resp.Meta.Count = count
+
+	return c.JSON(http.StatusOK, &resp)
+}
+
+func (ctl *BlogCommentController) Create(c echo.Context) error {
+	ctx := c.(*catu.RequestContext)
+
+	post, err := findCommentsBlogPost(c)
+	if err != nil {
+		return err
+	}
+
+	if !post.CanReceiveComments() {
+		return echo.NewHTTPError(http.StatusForbidden, "this blog post does not accept comments")
+	}
+
+	var body BlogCommentBodyRequest
+
+	if err := c.Bind(&body); err != nil {
+		if _, ok := err.(*echo.HTTPError); ok {
+			return err
+		}
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	record := body.Record
+	if record == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "blog-comment is required")
+	}
+
+	// fields set by the server:
+	record.ID = 0
+	record.BlogPostID = post.ID
+	record.AuthorID = getAuthenticatedUserID(ctx)
+	record.IP = ctx.RealIP()
+	record.Body = strings.TrimSpace(record.Body)
+	record.AuthorName = strings.TrimSpace(record.AuthorName)
+
+	if record.AuthorID != nil {
+		record.AuthorName = ctx.AuthenticatedUser.GetDisplayName()
+		record.AuthorEmail = ctx.AuthenticatedUser.GetEmail()
+	}
+
+	if record.Body == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "body is required")
+	}
+
+	if utf8.RuneCountInString(record.Body) > blogCommentMaxLength {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("body must have at most %d characters", blogCommentMaxLength))
+	}
+
+	if record.AuthorName == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "authorName is required")
+	}
+
+	canModerate := CanManageBlogPosts(ctx, "moderate_blog-comment", post.BlogID)
+
+	if record.ParentID != nil {
+		err = checkBlogCommentParent(post, *record.ParentID, canModerate)
+		if err != nil {
+			return err
+		}
+	}
+
+	if canModerate {
+		record.Status = BlogCommentStatusApproved
+	} else {
+		record.Status = BlogCommentStatusPending
+
+		if ctl.Guard != nil {
+			result := ctl.Guard.Check(body.toSubmission(record))
+			if result.RateLimited {
+				return echo.NewHTTPError(http.StatusTooManyRequests, "too many comments, try again later")
+			}
+
+			if result.Spam {
+				record.Status = BlogCommentStatusSpam
+
+				logrus.WithFields(logrus.Fields{
+					"blogPostId": post.ID,
+					"ip":         record.IP,
+					"reasons":    result.Reasons,
+				}).Info("BlogCommentController.Create comment flagged as spam")
+			}
+		}
+	}
+
+	err = record.Save()
+	if err != nil {
+		return errors.Wrap(err, "BlogCommentController.Create error on save comment")
+	}
+
+	ctl.fireCommentEvent("blog-comment-created", post, record)
+
+	resp := BlogCommentFindOneJSONResponse{
+		Record: record,
+	}
+
+	return c.JSON(http.StatusCreated, &resp)
+}
+
+// FormToken - New signed token for the comment form, send it back in the formToken field on create
+func (ctl *BlogCommentController) FormToken(c echo.Context) error {
+	post, err := findCommentsBlogPost(c)
+	if err != nil {
+		return err
+	}
+
+	if !post.CanReceiveComments() {
+		return echo.NewHTTPError(http.StatusForbidden, "this blog post does not accept comments")
+	}
+
+	token := ""
+	if ctl.Guard != nil {
+		token = ctl.Guard.NewFormToken()
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{"formToken": token})
+}
+
+func (ctl *BlogCommentController) FindOne(c echo.Context) error {
+	ctx := c.(*catu.RequestContext)
+
+	post, err := findCommentsBlogPost(c)
+	if err != nil {
+		return err
+	}
+
+	record, err := findBlogComment(post, c.Param("commentId"))
+	if err != nil {
+		return err
+	}
+
+	canModerate := CanManageBlogPosts(ctx, "moderate_blog-comment", post.BlogID)
+	if !canModerate {
+		if record.Status != BlogCommentStatusApproved {
+			return echo.NewHTTPError(http.StatusNotFound, "blog comment not found")
+		}
+
+		record.AuthorEmail = ""
+	}
+
+	resp := BlogCommentFindOneJSONResponse{
+		Record: record,
+	}
+
+	return c.JSON(http.StatusOK, &resp)
+}
+
+// Update - Moderate one comment, only the status and body can be changed
+func (ctl *BlogCommentController) Update(c echo.Context) error {
+	ctx := c.(*catu.RequestContext)
+
+	post, err := findCommentsBlogPost(c)
+	if err != nil {
+		return err
+	}
+
+	if !CanManageBlogPosts(ctx, "moderate_blog-comment", post.BlogID) {
+		return echo.NewHTTPError(http.StatusForbidden, "Forbidden")
+	}
+
+	record, err := findBlogComment(post, c.Param("commentId"))
+	if err != nil {
+		return err
+	}
+
+	oldStatus := record.Status
+
+	var body BlogCommentBodyRequest
+
+	if err := c.Bind(&body); err != nil {
+		if _, ok := err.(*echo.HTTPError); ok {
+			return err
+		}
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	if body.Record == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "blog-comment is required")
+	}
+
+	if body.Record.Status != "" {
+		if !IsValidBlogCommentStatus(body.Record.Status) {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid status")
+		}
+		record.Status = body.Record.Status
+	}
+
+	if strings.TrimSpace(body.Record.Body) != "" {
+		record.Body = strings.TrimSpace(body.Record.Body)
+	}
+
+	err = record.Save()
+	if err != nil {
+		return errors.Wrap(err, "BlogCommentController.Update error on save comment")
+	}
+
+	if oldStatus != record.Status {
+		logrus.WithFields(logrus.Fields{
+			"id":   record.ID,
+			"from": oldStatus,
+			"to":   record.Status,
+		}).Info("BlogCommentController.Update comment status changed")
+
+		ctl.fireCommentEvent("blog-comment-status-changed", post, record)
+	}
+
+	resp := BlogCommentFindOneJSONResponse{
+		Record: record,
+	}
+
+	return c.JSON(http.StatusOK, &resp)
+}
+
+func (ctl *BlogCommentController) Delete(c echo.Context) error {
+	ctx := c.(*catu.RequestContext)
+
+	post, err := findCommentsBlogPost(c)
+	if err != nil {
+		return err
+	}
+
+	if !CanManageBlogPosts(ctx, "moderate_blog-comment", post.BlogID) {
+		return echo.NewHTTPError(http.StatusForbidden, "Forbidden")
+	}
+
+	record, err := findBlogComment(post, c.Param("commentId"))
+	if err != nil {
+		return err
+	}
+
+	err = record.Delete()
+	if err != nil {
+		return errors.Wrap(err, "BlogCommentController.Delete error on delete comment")
+	}
+
+	ctl.fireCommentEvent("blog-comment-deleted", post, record)
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (ctl *BlogCommentController) fireCommentEvent(name string, post *BlogPostModel, record *BlogCommentModel) {
+	err, _ := ctl.App.GetEvents().Fire(name, event.M{
+		"app":      ctl.App,
+		"blogPost": post,
+		"comment":  record,
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"event":      name,
+			"blogPostId": post.ID,
+			"id":         record.ID,
+			"error":      fmt.Sprintf("%+v\n", err),
+		}).Error("BlogCommentController error on fire event")
+	}
+}
+
+func (r *BlogCommentBodyRequest) toSubmission(record *BlogCommentModel) *Submission {
+	s := Submission{
+		IP:        record.IP,
+		UserID:    record.AuthorID,
+		Honeypot:  r.Website,
+		FormToken: r.FormToken,
+		Texts:     []string{record.AuthorName, record.Body},
+	}
+
+	return &s
+}
+
+// findCommentsBlogPost - Find the blog post from route param and check if the user can see it
+func findCommentsBlogPost(c echo.Context) (*BlogPostModel, error) {
+	ctx := c.(*catu.RequestContext)
+
+	var post BlogPostModel
+	err := BlogPostFindOne(c.Param("id"), &post)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return nil, errors.Wrap(err, "error on find blog post") // unknow error
+		}
+		return nil, &catu.HTTPError{
+			Code:     404,
+			Message:  "blog post not found",
+			Internal: err,
+		}
+	}
+
+	if !post.Published && !CanManageBlogPosts(ctx, "access_contents_unpublished", post.BlogID) {
+		return nil, echo.NewHTTPError(http.StatusForbidden, "Forbidden")
+	}
+
+	return &post, nil
+}
+
+func findBlogComment(post *BlogPostModel, id string) (*BlogCommentModel, error) {
+	var record BlogCommentModel
+	err := BlogCommentFindOne(post.GetIDString(), id, &record)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return nil, errors.Wrap(err, "error on find blog comment") // unknow error
+		}
+		return nil, &catu.HTTPError{
+			Code:     404,
+			Message:  "blog comment not found",
+			Internal: err,
+		}
+	}
+
+	return &record, nil
+}
+
+// checkBlogCommentParent - Check if the comment can be a reply of the parent comment.
+// Only moderators can reply to pending or spam comments, for other users they don't exist
+func checkBlogCommentParent(post *BlogPostModel, parentID uint64, canModerate bool) error {
+	var parent BlogCommentModel
+	err := BlogCommentFindOne(post.GetIDString(), fmt.Sprint(parentID), &parent)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusBadRequest, "parent comment not found")
+		}
+		return errors.Wrap(err, "error on find parent comment")
+	}
+
+	if parent.Status != BlogCommentStatusApproved && !canModerate {
+		return echo.NewHTTPError(http.StatusBadRequest, "parent comment not found")
+	}
+
+	return nil
+}
+
+type BlogCommentControllerCfg struct {
+	App   catu.App
+	Guard *SubmissionGuard
+}
+
+func NewBlogCommentController(cfg *BlogCommentControllerCfg) *BlogCommentController {
+	ctx := BlogCommentController{App: cfg.App, Guard: cfg.Guard}
+
+	return &ctx
+}
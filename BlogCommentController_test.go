@@ -0,0 +1,61 @@
+package blog
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestCheckBlogCommentParent(t *testing.T) {
+	db := newTestDB(t)
+
+	err := db.Migrator().CreateTable(&BlogCommentModel{})
+	if err != nil {
+		t.Fatalf("error on create blog comments table: %v", err)
+	}
+
+	post := BlogPostModel{ID: 1}
+	for _, comment := range []*BlogCommentModel{
+		{ID: 1, BlogPostID: 1, AuthorName: "Ana", Body: "approved", Status: BlogCommentStatusApproved},
+		{ID: 2, BlogPostID: 1, AuthorName: "Ana", Body: "pending", Status: BlogCommentStatusPending},
+		{ID: 3, BlogPostID: 1, AuthorName: "Ana", Body: "spam", Status: BlogCommentStatusSpam},
+		{ID: 4, BlogPostID: 2, AuthorName: "Ana", Body: "other post", Status: BlogCommentStatusApproved},
+	} {
+		err = db.Create(comment).Error
+		if err != nil {
+			t.Fatalf("error on create blog comment: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		parentID    uint64
+		canModerate bool
+		valid       bool
+	}{
+		{"approved parent", 1, false, true},
+		{"pending parent", 2, false, false},
+		{"spam parent", 3, false, false},
+		{"parent from other post", 4, false, false},
+		{"missing parent", 5, false, false},
+		{"pending parent with moderator", 2, true, true},
+		{"spam parent with moderator", 3, true, true},
+		{"missing parent with moderator", 5, true, false},
+	}
+
+	for _, tt := range tests {
+		err := checkBlogCommentParent(&post, tt.parentID, tt.canModerate)
+		if tt.valid {
+			if err != nil {
+				t.Errorf("%s: unexpected error %v", tt.name, err)
+			}
+			continue
+		}
+
+		httpErr, ok := err.(*echo.HTTPError)
+		if !ok || httpErr.Code != http.StatusBadRequest {
+			t.Errorf("%s: error = %v, want bad request", tt.name, err)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package blog
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/go-catupiry/catu"
+	"github.com/sirupsen/logrus"
+)
+
+// Blog comment moderation states
+const (
+	BlogCommentStatusPending  = "pending"
+	BlogCommentStatusApproved = "approved"
+	BlogCommentStatusSpam     = "spam"
+)
+
+// max comment body size
+const blogCommentMaxLength = 5000
+
+// BlogCommentModel Stores one comment in a blog post, replies are linked with ParentID
+type BlogCommentModel struct {
+	ID          uint64    `gorm:"primaryKey;column:id" json:"id"`
+	BlogPostID  uint64    `gorm:"index:blogCommentPostId;column:blogPostId;not null" json:"blogPostId"`
+	ParentID    *uint64   `gorm:"index:blogCommentParentId;column:parentId" json:"parentId"`
+	AuthorID    *uint64   `gorm:"index:blogCommentAuthorId;column:authorId" json:"authorId,string"`
+	AuthorName  string    `gorm:"column:authorName;type:varchar(100)" json:"authorName"`
+	AuthorEmail string    `gorm:"column:authorEmail;type:varchar(255)" json:"authorEmail,omitempty"`
+	Body        string    `gorm:"column:body;type:text;not null" json:"body"`
+	Status      string    `gorm:"index:blogCommentStatus;column:status;type:varchar(20);not null;default:pending" json:"status"`
+	IP          string    `gorm:"column:ip;type:varchar(45)" json:"-"`
+	CreatedAt   time.Time `gorm:"column:createdAt;type:datetime;not null" json:"createdAt"`
+	UpdatedAt   time.Time `gorm:"column:updatedAt;type:datetime;not null" json:"updatedAt"`
+
+	Children []*BlogCommentModel `gorm:"-" json:"children,omitempty"`
+}
+
+// TableName get sql table name
+func (m *BlogCommentModel) TableName() string {
+	return "blog_comments"
+}
+
+func (m *BlogCommentModel) GetIDString() string {
+	return strconv.FormatUint(m.ID, 10)
+}
+
+func (m *BlogCommentModel) Save() error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	if m.Status == "" {
+		m.Status = BlogCommentStatusPending
+	}
+
+	if m.ID == 0 {
+		return db.Create(m).Error
+	}
+
+	return db.Save(m).Error
+}
+
+// Delete - Delete the comment and move the replies to the comment parent
+func (m *BlogCommentModel) Delete() error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	err := db.Model(&BlogCommentModel{}).
+		Where("parentId = ?", m.ID).
+		Update("parentId", m.ParentID).Error
+	if err != nil {
+		return err
+	}
+
+	return db.Delete(m).Error
+}
+
+func IsValidBlogCommentStatus(status string) bool {
+	switch status {
+	case BlogCommentStatusPending, BlogCommentStatusApproved, BlogCommentStatusSpam:
+		return true
+	}
+
+	return false
+}
+
+// BlogCommentFindOne - Find one comment from the blog post
+func BlogCommentFindOne(blogPostID, id string, record *BlogCommentModel) error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	return db.
+		Where("blogPostId = ? AND id = ?", blogPostID, id).
+		First(record).Error
+}
+
+type BlogCommentQueryOpts struct {
+	BlogPostID string
+	// empty for all status
+	Status  string
+	Records *[]*BlogCommentModel
+	Count   *int64
+	Limit   int
+	Offset  int
+}
+
+// BlogCommentQueryAndCount - Find the blog post comments, oldest first
+func BlogCommentQueryAndCount(opts *BlogCommentQueryOpts) error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	query := db.Model(&BlogCommentModel{}).
+		Where("blogPostId = ?", opts.BlogPostID)
+
+	if opts.Status != "" {
+		query = query.Where("status = ?", opts.Status)
+	}
+
+	err := query.Count(opts.Count).Error
+	if err != nil {
+		return err
+	}
+
+	if opts.Limit > 0 {
+		query = query.Limit(opts.Limit).Offset(opts.Offset)
+	}
+
+	return query.
+		Order("createdAt ASC").
+		Order("id ASC").
+		Find(opts.Records).Error
+}
+
+func BlogCommentDeleteAll(blogPostID string) error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	return db.
+		Where("blogPostId = ?", blogPostID).
+		Delete(&BlogCommentModel{}).Error
+}
+
+// BuildBlogCommentsTree - Set the comment replies in Children and return the root comments.
+// Replies to comments not in the list are returned as root comments
+func BuildBlogCommentsTree(records []*BlogCommentModel) []*BlogCommentModel {
+	byID := map[uint64]*BlogCommentModel{}
+	for _, r := range records {
+		r.Children = nil
+		byID[r.ID] = r
+	}
+
+	roots := []*BlogCommentModel{}
+	for _, r := range records {
+		if r.ParentID != nil {
+			if parent, ok := byID[*r.ParentID]; ok && parent != r {
+				parent.Children = append(parent.Children, r)
+				continue
+			}
+		}
+
+		roots = append(roots, r)
+	}
+
+	return roots
+}
+
+// BlogPostCommentsTPL - Comments data used in the blog post page template
+type BlogPostCommentsTPL struct {
+	Comments   []*BlogCommentModel
+	Count      int64
+	CanComment bool
+}
+
+// LoadBlogPostCommentsTemplateData - Set the approved comments tree in the "comments" context variable
+func LoadBlogPostCommentsTemplateData(ctx *catu.RequestContext, post *BlogPostModel) error {
+	data := BlogPostCommentsTPL{
+		Comments:   []*BlogCommentModel{},
+		CanComment: post.CanReceiveComments(),
+	}
+
+	records := []*BlogCommentModel{}
+	err := BlogCommentQueryAndCount(&BlogCommentQueryOpts{
+		BlogPostID: post.GetIDString(),
+		Status:     BlogCommentStatusApproved,
+		Records:    &records,
+		Count:      &data.Count,
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"blogPostId": post.ID,
+			"error":      err,
+		}).Error("LoadBlogPostCommentsTemplateData error on find comments")
+		return err
+	}
+
+	for _, r := range records {
+		r.AuthorEmail = ""
+	}
+
+	data.Comments = BuildBlogCommentsTree(records)
+
+	ctx.Set("comments", &data)
+
+	return nil
+}
+
+// CanReceiveComments - Only published posts with AllowComments accept new comments
+func (r *BlogPostModel) CanReceiveComments() bool {
+	return r.AllowComments && r.Published
+}
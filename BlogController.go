@@ -27,6 +27,10 @@ type BlogBodyRequest struct {
 	Blog *BlogModel `json:"blog"`
 }
 
+type BlogTagCloudJSONResponse struct {
+	Records []*BlogTagCount `json:"tag"`
+}
+
 type BlogTeaserTPL struct {
 	Ctx    *catu.RequestContext
 	Record *BlogModel
@@ -115,11 +119,22 @@ func (ctl *BlogController) Create(c echo.Context) error {
 		"body": body,
 	}).Info("BlogController.Create params")
 
+	// the creator is always the authenticated user
+	userID := getAuthenticatedUserID(ctx)
+	record.CreatorID = nil
+	if userID != nil {
+		creatorID := int64(*userID)
+		record.CreatorID = &creatorID
+	}
+	record.UpdatedByID = userID
+
 	err = record.Save()
 	if err != nil {
 		return err
 	}
 
+	auditBlog(NewBlogAuditActorFromCtx(ctx), BlogAuditActionCreate, record, nil, record.AuditFields())
+
 	err = record.LoadData()
 	if err != nil {
 		return err
@@ -217,6 +232,9 @@ func (ctl *BlogController) Update(c echo.Context) error {
 
 	record.LoadData()
 
+	oldCreatorID := record.CreatorID
+	before := record.AuditFields()
+
 	body := BlogFindOneJSONResponse{Blog: &record}
 
 	if err := c.Bind(&body); err != nil {
@@ -231,10 +249,15 @@ func (ctl *BlogController) Update(c echo.Context) error {
 		return c.NoContent(http.StatusNotFound)
 	}
 
+	record.CreatorID = oldCreatorID
+	record.UpdatedByID = getAuthenticatedUserID(RequestContext)
+
 	err = record.Save()
 	if err != nil {
 		return err
 	}
+
+	auditBlog(NewBlogAuditActorFromCtx(RequestContext), BlogAuditActionUpdate, &record, before, record.AuditFields())
 	resp := BlogFindOneJSONResponse{
 		Blog: &record,
 	}
@@ -268,14 +291,44 @@ func (ctl *BlogController) Delete(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusForbidden, "Forbidden")
 	}
 
+	before := record.AuditFields()
+
 	err = record.Delete()
 	if err != nil {
 		return err
 	}
 
+	auditBlog(NewBlogAuditActorFromCtx(RequestContext), BlogAuditActionDelete, &record, before, nil)
+
 	return c.NoContent(http.StatusNoContent)
 }
 
+// TagCloud - Most used tags in the blog published posts with the posts count
+func (ctl *BlogController) TagCloud(c echo.Context) error {
+	ctx := c.(*catu.RequestContext)
+
+	var blog BlogModel
+	err := BlogFindOne(c.Param("id"), &blog)
+	if err != nil {
+		if isNotFoundError(err) {
+			return echo.NewHTTPError(http.StatusNotFound, "blog not found")
+		}
+		return errors.Wrap(err, "BlogController.TagCloud error on find blog")
+	}
+
+	records := []*BlogTagCount{}
+	err = BlogTagCloud(&blog.ID, ctx.GetLimit(), &records)
+	if err != nil {
+		return errors.Wrap(err, "BlogController.TagCloud error on find tags")
+	}
+
+	resp := BlogTagCloudJSONResponse{
+		Records: records,
+	}
+
+	return c.JSON(http.StatusOK, &resp)
+}
+
 func (ctl *BlogController) FindAllPageHandler(c echo.Context) error {
 	var err error
 	RequestContext := c.(*catu.RequestContext)
@@ -381,10 +434,11 @@ func (ctl *BlogController) FindOnePageHandler(c echo.Context) error {
 	ctx.Title = record.Title
 	ctx.BodyClass = append(ctx.BodyClass, "body-content-findOne")
 
-	ctx.MetaTags.Title = record.Title
-	ctx.MetaTags.Description = record.Description
-	if record.HasLogo {
-		ctx.MetaTags.ImageURL = record.Logo[0].URLs["medium"]
+	err = LoadBlogPageMetadata(ctx, &record)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"err": err.Error(),
+		}).Error("FindOnePageHandler error on load blog metadata")
 	}
 
 	err = LoadPageBlogPosts(ctx)
@@ -442,6 +496,8 @@ func LoadPageBlogPosts(ctx *catu.RequestContext) error {
 		"len_records_found": len(records),
 	}).Debug("BlogPostController.FindAllPageHandler count result")
 
+	preloadBlogPostsBlogSlugs(records)
+
 	for i := range records {
 		records[i].LoadTeaserData()
 
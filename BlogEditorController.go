@@ -0,0 +1,275 @@
+package blog
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-catupiry/catu"
+	"github.com/gookit/event"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type BlogEditorJSONResponse struct {
+	catu.BaseListReponse
+	Records []*BlogEditorsModel `json:"blog-editor"`
+}
+
+type BlogEditorFindOneJSONResponse struct {
+	Record *BlogEditorsModel `json:"blog-editor"`
+}
+
+type BlogEditorBodyRequest struct {
+	Record *BlogEditorsModel `json:"blog-editor"`
+}
+
+// Http blog editor controller | struct with http handlers to manage blog editors
+type BlogEditorController struct {
+	App catu.App
+}
+
+func (ctl *BlogEditorController) Query(c echo.Context) error {
+	ctx := c.(*catu.RequestContext)
+
+	blog, err := findEditorsBlog(c)
+	if err != nil {
+		return err
+	}
+
+	if !CanManageBlogEditors(ctx, blog.ID) {
+		return echo.NewHTTPError(http.StatusForbidden, "Forbidden")
+	}
+
+	records := []*BlogEditorsModel{}
+	err = BlogEditorFindMany(blog.GetIDString(), &records)
+	if err != nil {
+		return errors.Wrap(err, "BlogEditorController.Query error on find editors")
+	}
+
+	for i := range records {
+		err = records[i].LoadUser()
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"blogId": blog.ID,
+				"userId": records[i].UserID,
+				"error":  err,
+			}).Warn("BlogEditorController.Query error on load editor user")
+		}
+	}
+
+	resp := BlogEditorJSONResponse{
+		Records: records,
+	}
+
+	resp.Meta.Count = int64(len(records))
+
+	return c.JSON(http.StatusOK, &resp)
+}
+
+func (ctl *BlogEditorController) Create(c echo.Context) error {
+	ctx := c.(*catu.RequestContext)
+
+	blog, err := findEditorsBlog(c)
+	if err != nil {
+		return err
+	}
+
+	if !CanManageBlogEditors(ctx, blog.ID) {
+		return echo.NewHTTPError(http.StatusForbidden, "Forbidden")
+	}
+
+	var body BlogEditorBodyRequest
+
+	if err := c.Bind(&body); err != nil {
+		if _, ok := err.(*echo.HTTPError); ok {
+			return err
+		}
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	record := body.Record
+	if record == nil || record.UserID == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "userId is required")
+	}
+
+	record.BlogID = int64(blog.ID)
+
+	if record.Role == "" {
+		record.Role = BlogEditorRoleEditor
+	}
+
+	if !IsValidBlogEditorRole(record.Role) {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid role")
+	}
+
+	var saved BlogEditorsModel
+	err = BlogEditorFindOne(blog.GetIDString(), record.GetUserIDString(), &saved)
+	if err == nil {
+		return echo.NewHTTPError(http.StatusConflict, "user is already one blog editor")
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.Wrap(err, "BlogEditorController.Create error on find editor")
+	}
+
+	err = record.LoadUser()
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusBadRequest, "user not found")
+		}
+		return errors.Wrap(err, "BlogEditorController.Create error on find user")
+	}
+
+	err = record.Save()
+	if err != nil {
+		return errors.Wrap(err, "BlogEditorController.Create error on save editor")
+	}
+
+	auditBlogEditor(NewBlogAuditActorFromCtx(ctx), blog, record.UserID, "", record.Role)
+
+	ctl.fireEditorEvent("blog-editor-added", blog, record)
+
+	resp := BlogEditorFindOneJSONResponse{
+		Record: record,
+	}
+
+	return c.JSON(http.StatusCreated, &resp)
+}
+
+func (ctl *BlogEditorController) Update(c echo.Context) error {
+	ctx := c.(*catu.RequestContext)
+
+	blog, err := findEditorsBlog(c)
+	if err != nil {
+		return err
+	}
+
+	if !CanManageBlogEditors(ctx, blog.ID) {
+		return echo.NewHTTPError(http.StatusForbidden, "Forbidden")
+	}
+
+	var record BlogEditorsModel
+	err = BlogEditorFindOne(blog.GetIDString(), c.Param("userId"), &record)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "blog editor not found")
+		}
+		return errors.Wrap(err, "BlogEditorController.Update error on find editor")
+	}
+
+	oldRole := record.Role
+	userID := record.UserID
+
+	body := BlogEditorBodyRequest{Record: &record}
+
+	if err := c.Bind(&body); err != nil {
+		if _, ok := err.(*echo.HTTPError); ok {
+			return err
+		}
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	// only the role can be changed:
+	record.BlogID = int64(blog.ID)
+	record.UserID = userID
+
+	if !IsValidBlogEditorRole(record.Role) {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid role")
+	}
+
+	err = record.Save()
+	if err != nil {
+		return errors.Wrap(err, "BlogEditorController.Update error on save editor")
+	}
+
+	record.LoadUser()
+
+	if oldRole != record.Role {
+		auditBlogEditor(NewBlogAuditActorFromCtx(ctx), blog, record.UserID, oldRole, record.Role)
+
+		ctl.fireEditorEvent("blog-editor-updated", blog, &record)
+	}
+
+	resp := BlogEditorFindOneJSONResponse{
+		Record: &record,
+	}
+
+	return c.JSON(http.StatusOK, &resp)
+}
+
+func (ctl *BlogEditorController) Delete(c echo.Context) error {
+	ctx := c.(*catu.RequestContext)
+
+	blog, err := findEditorsBlog(c)
+	if err != nil {
+		return err
+	}
+
+	if !CanManageBlogEditors(ctx, blog.ID) {
+		return echo.NewHTTPError(http.StatusForbidden, "Forbidden")
+	}
+
+	var record BlogEditorsModel
+	err = BlogEditorFindOne(blog.GetIDString(), c.Param("userId"), &record)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.JSON(http.StatusNotFound, make(map[string]string))
+		}
+		return errors.Wrap(err, "BlogEditorController.Delete error on find editor")
+	}
+
+	err = record.Delete()
+	if err != nil {
+		return errors.Wrap(err, "BlogEditorController.Delete error on delete editor")
+	}
+
+	auditBlogEditor(NewBlogAuditActorFromCtx(ctx), blog, record.UserID, record.Role, "")
+
+	ctl.fireEditorEvent("blog-editor-removed", blog, &record)
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (ctl *BlogEditorController) fireEditorEvent(name string, blog *BlogModel, record *BlogEditorsModel) {
+	err, _ := ctl.App.GetEvents().Fire(name, event.M{
+		"app":    ctl.App,
+		"blog":   blog,
+		"editor": record,
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"event":  name,
+			"blogId": record.BlogID,
+			"userId": record.UserID,
+			"error":  fmt.Sprintf("%+v\n", err),
+		}).Error("BlogEditorController error on fire event")
+	}
+}
+
+func findEditorsBlog(c echo.Context) (*BlogModel, error) {
+	var blog BlogModel
+	err := BlogFindOne(c.Param("id"), &blog)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return nil, errors.Wrap(err, "error on find blog") // unknow error
+		}
+		return nil, &catu.HTTPError{
+			Code:     404,
+			Message:  "blog not found",
+			Internal: err,
+		}
+	}
+
+	return &blog, nil
+}
+
+type BlogEditorControllerCfg struct {
+	App catu.App
+}
+
+func NewBlogEditorController(cfg *BlogEditorControllerCfg) *BlogEditorController {
+	ctx := BlogEditorController{App: cfg.App}
+
+	return &ctx
+}
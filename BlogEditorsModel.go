@@ -1,14 +1,164 @@
 package blog
 
-import "time"
+import (
+	"strconv"
+	"time"
+
+	"github.com/go-catupiry/catu"
+	"github.com/go-catupiry/user"
+)
+
+const (
+	// Owners can manage the blog editors and all blog posts
+	BlogEditorRoleOwner = "owner"
+	// Editors can create, update, publish and delete blog posts
+	BlogEditorRoleEditor = "editor"
+	// Contributors can only create and update blog posts
+	BlogEditorRoleContributor = "contributor"
+)
+
+var blogEditorRoles = []string{BlogEditorRoleOwner, BlogEditorRoleEditor, BlogEditorRoleContributor}
+
+// permissions that contributors can use in their blogs
+var blogContributorPermissions = []string{
+	"create_blog-post",
+	"update_blog-post",
+	"access_contents_unpublished",
+	"access_blogs_unpublished",
+}
 
 type BlogEditorsModel struct {
 	BlogID    int64     `gorm:"primaryKey;index:PRIMARY,unique;column:blog_id;type:int(11);not null" json:"blogId"`
 	UserID    int64     `gorm:"primaryKey;index:PRIMARY,unique;column:user_id;type:int(11);not null" json:"userId"`
+	Role      string    `gorm:"column:role;type:varchar(20);not null;default:editor" json:"role"`
 	CreatedAt time.Time `gorm:"column:created_at;type:datetime;not null" json:"createdAt"`
 	UpdatedAt time.Time `gorm:"column:updated_at;type:datetime;not null" json:"updatedAt"`
+
+	User *user.UserModel `gorm:"-" json:"user"`
 }
 
 func (m *BlogEditorsModel) TableName() string {
 	return "blogs-editors"
 }
+
+// Save - Create the blog editor if not exists or update the role
+func (m *BlogEditorsModel) Save() error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	if m.Role == "" {
+		m.Role = BlogEditorRoleEditor
+	}
+
+	var count int64
+	err := db.Model(&BlogEditorsModel{}).
+		Where("blog_id = ? AND user_id = ?", m.BlogID, m.UserID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return db.Create(m).Error
+	}
+
+	m.UpdatedAt = time.Now()
+
+	return db.Model(&BlogEditorsModel{}).
+		Where("blog_id = ? AND user_id = ?", m.BlogID, m.UserID).
+		Updates(map[string]interface{}{"role": m.Role, "updated_at": m.UpdatedAt}).Error
+}
+
+func (m *BlogEditorsModel) Delete() error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	return db.
+		Where("blog_id = ? AND user_id = ?", m.BlogID, m.UserID).
+		Delete(&BlogEditorsModel{}).Error
+}
+
+func (m *BlogEditorsModel) LoadUser() error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	var u user.UserModel
+	err := db.First(&u, m.UserID).Error
+	if err != nil {
+		return err
+	}
+
+	m.User = &u
+
+	return nil
+}
+
+func (m *BlogEditorsModel) GetUserIDString() string {
+	return strconv.FormatInt(m.UserID, 10)
+}
+
+// BlogEditorFindOne - Find one blog editor membership
+func BlogEditorFindOne(blogID, userID string, record *BlogEditorsModel) error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	return db.
+		Where("blog_id = ? AND user_id = ?", blogID, userID).
+		First(record).Error
+}
+
+// BlogEditorFindMany - Find all editors from one blog
+func BlogEditorFindMany(blogID string, records *[]*BlogEditorsModel) error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	return db.
+		Where("blog_id = ?", blogID).
+		Order("created_at ASC").
+		Find(records).Error
+}
+
+// BlogEditorFindRole - Get the user role in one blog, returns a empty string if the user isn't one editor
+func BlogEditorFindRole(blogID uint64, userID string) (string, error) {
+	db := catu.GetDefaultDatabaseConnection()
+
+	var records []BlogEditorsModel
+	err := db.
+		Where("blog_id = ? AND user_id = ?", blogID, userID).
+		Limit(1).
+		Find(&records).Error
+	if err != nil {
+		return "", err
+	}
+
+	if len(records) == 0 {
+		return "", nil
+	}
+
+	if records[0].Role == "" {
+		return BlogEditorRoleEditor, nil
+	}
+
+	return records[0].Role, nil
+}
+
+func IsValidBlogEditorRole(role string) bool {
+	for i := range blogEditorRoles {
+		if blogEditorRoles[i] == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// BlogEditorRoleCan - Check if one blog editor role have the permission in the blog
+func BlogEditorRoleCan(role, permission string) bool {
+	switch role {
+	case BlogEditorRoleOwner, BlogEditorRoleEditor:
+		return true
+	case BlogEditorRoleContributor:
+		for i := range blogContributorPermissions {
+			if blogContributorPermissions[i] == permission {
+				return true
+			}
+		}
+	}
+
+	return false
+}
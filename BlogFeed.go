@@ -0,0 +1,336 @@
+package blog
+
+import (
+	"encoding/xml"
+	"strconv"
+	"time"
+
+	"github.com/go-catupiry/catu"
+	"github.com/go-catupiry/files"
+	"github.com/sirupsen/logrus"
+)
+
+// RSSFeed RSS 2.0 document
+type RSSFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel RSSChannel `xml:"channel"`
+}
+
+type RSSChannel struct {
+	Title         string     `xml:"title"`
+	Link          string     `xml:"link"`
+	Description   string     `xml:"description"`
+	Language      string     `xml:"language,omitempty"`
+	LastBuildDate string     `xml:"lastBuildDate,omitempty"`
+	Items         []*RSSItem `xml:"item"`
+}
+
+type RSSItem struct {
+	Title       string        `xml:"title"`
+	Link        string        `xml:"link"`
+	GUID        RSSGUID       `xml:"guid"`
+	Description string        `xml:"description"`
+	PubDate     string        `xml:"pubDate,omitempty"`
+	Categories  []string      `xml:"category"`
+	Enclosure   *RSSEnclosure `xml:"enclosure"`
+}
+
+type RSSGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type RSSEnclosure struct {
+	URL    string `xml:"url,attr"`
+	Length int64  `xml:"length,attr"`
+	Type   string `xml:"type,attr"`
+}
+
+// AtomFeed Atom 1.0 document
+type AtomFeed struct {
+	XMLName  xml.Name     `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string       `xml:"id"`
+	Title    string       `xml:"title"`
+	Subtitle string       `xml:"subtitle,omitempty"`
+	Updated  string       `xml:"updated"`
+	Links    []AtomLink   `xml:"link"`
+	Author   *AtomAuthor  `xml:"author"`
+	Entries  []*AtomEntry `xml:"entry"`
+}
+
+type AtomLink struct {
+	Href   string `xml:"href,attr"`
+	Rel    string `xml:"rel,attr,omitempty"`
+	Type   string `xml:"type,attr,omitempty"`
+	Length int64  `xml:"length,attr,omitempty"`
+}
+
+type AtomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type AtomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type AtomEntry struct {
+	ID         string         `xml:"id"`
+	Title      string         `xml:"title"`
+	Updated    string         `xml:"updated"`
+	Published  string         `xml:"published,omitempty"`
+	Links      []AtomLink     `xml:"link"`
+	Summary    string         `xml:"summary"`
+	Categories []AtomCategory `xml:"category"`
+}
+
+// BlogFeedData data shared by all feed formats
+type BlogFeedData struct {
+	Title       string
+	Description string
+	Link        string
+	FeedLink    string
+	Language    string
+	Updated     time.Time
+	Records     []*BlogPostModel
+}
+
+// NewBlogFeedData - Load feed posts and metadata for one blog or for all blogs if blog is nil
+func NewBlogFeedData(blog *BlogModel, feedPath string) (*BlogFeedData, error) {
+	cfg := catu.GetConfiguration()
+	origin := cfg.Get("APP_ORIGIN")
+	limit, _ := strconv.Atoi(cfg.GetF("BLOG_FEED_LIMIT", "20"))
+
+	d := BlogFeedData{
+		Title:       cfg.Get("SITE_NAME"),
+		Description: cfg.Get("SITE_DESCRIPTION"),
+		Link:        origin + "/blogs",
+		FeedLink:    origin + feedPath,
+		Language:    cfg.GetF("SITE_LANGUAGE", "pt-BR"),
+	}
+
+	blogID := ""
+	if blog != nil {
+		blogID = blog.GetIDString()
+		d.Title = blog.Title
+		d.Description = blog.Description
+		d.Link = blog.LinkPermanent
+	}
+
+	err := BlogPostFindInRSS(blogID, &d.Records, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	preloadBlogPostsBlogSlugs(d.Records)
+
+	for i := range d.Records {
+		d.Records[i].LoadFeedData()
+
+		if d.Records[i].UpdatedAt.After(d.Updated) {
+			d.Updated = d.Records[i].UpdatedAt
+		}
+	}
+
+	if d.Updated.IsZero() {
+		d.Updated = time.Now()
+	}
+
+	return &d, nil
+}
+
+// LoadFeedData - Load post data used in feed items
+func (r *BlogPostModel) LoadFeedData() error {
+	r.LoadPath()
+
+	err := r.LoadFeaturedImage()
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"id":    r.ID,
+			"error": err,
+		}).Error("BlogPostModel.LoadFeedData error on load featured image")
+	}
+
+	// feed item categories
+	r.RefreshTerms()
+
+	return nil
+}
+
+func (d *BlogFeedData) ToRSS() *RSSFeed {
+	feed := RSSFeed{
+		Version: "2.0",
+		Channel: RSSChannel{
+			Title:         d.Title,
+			Link:          d.Link,
+			Description:   d.Description,
+			Language:      d.Language,
+			LastBuildDate: d.Updated.Format(time.RFC1123Z),
+		},
+	}
+
+	for _, r := range d.Records {
+		item := RSSItem{
+			Title:       r.Title,
+			Link:        r.LinkPermanent,
+			GUID:        RSSGUID{IsPermaLink: true, Value: r.LinkPermanent},
+			Description: r.Teaser,
+			Categories:  r.Tags,
+		}
+
+		if r.PublishedAt != nil {
+			item.PubDate = r.PublishedAt.Format(time.RFC1123Z)
+		}
+
+		if image := getFeedImage(r); image != nil {
+			item.Enclosure = &RSSEnclosure{
+				URL:    image.URLs["original"],
+				Length: getFeedImageSize(image),
+				Type:   getFeedImageMime(image),
+			}
+		}
+
+		feed.Channel.Items = append(feed.Channel.Items, &item)
+	}
+
+	return &feed
+}
+
+func (d *BlogFeedData) ToAtom() *AtomFeed {
+	feed := AtomFeed{
+		ID:       d.FeedLink,
+		Title:    d.Title,
+		Subtitle: d.Description,
+		Updated:  d.Updated.Format(time.RFC3339),
+		Links: []AtomLink{
+			{Href: d.Link, Rel: "alternate", Type: "text/html"},
+			{Href: d.FeedLink, Rel: "self", Type: "application/atom+xml"},
+		},
+		Author: &AtomAuthor{Name: catu.GetConfiguration().Get("SITE_NAME")},
+	}
+
+	for _, r := range d.Records {
+		entry := AtomEntry{
+			ID:      r.LinkPermanent,
+			Title:   r.Title,
+			Updated: r.UpdatedAt.Format(time.RFC3339),
+			Links: []AtomLink{
+				{Href: r.LinkPermanent, Rel: "alternate", Type: "text/html"},
+			},
+			Summary: r.Teaser,
+		}
+
+		if r.PublishedAt != nil {
+			entry.Published = r.PublishedAt.Format(time.RFC3339)
+		}
+
+		for _, t := range r.Tags {
+			entry.Categories = append(entry.Categories, AtomCategory{Term: t})
+		}
+
+		if image := getFeedImage(r); image != nil {
+			entry.Links = append(entry.Links, AtomLink{
+				Href:   image.URLs["original"],
+				Rel:    "enclosure",
+				Type:   getFeedImageMime(image),
+				Length: getFeedImageSize(image),
+			})
+		}
+
+		feed.Entries = append(feed.Entries, &entry)
+	}
+
+	return &feed
+}
+
+func getFeedImage(r *BlogPostModel) *files.ImageModel {
+	if len(r.FeaturedImage) == 0 || r.FeaturedImage[0].URLs["original"] == "" {
+		return nil
+	}
+
+	return r.FeaturedImage[0]
+}
+
+func getFeedImageSize(image *files.ImageModel) int64 {
+	if image.Size == nil {
+		return 0
+	}
+
+	return *image.Size
+}
+
+func getFeedImageMime(image *files.ImageModel) string {
+	if image.Mime == nil {
+		return "image/jpeg"
+	}
+
+	return *image.Mime
+}
+
+// JSONFeed JSON Feed 1.1 document, see https://jsonfeed.org/version/1.1
+type JSONFeed struct {
+	Version     string          `json:"version"`
+	Title       string          `json:"title"`
+	HomePageURL string          `json:"home_page_url,omitempty"`
+	FeedURL     string          `json:"feed_url,omitempty"`
+	Description string          `json:"description,omitempty"`
+	NextURL     string          `json:"next_url,omitempty"`
+	Language    string          `json:"language,omitempty"`
+	Items       []*JSONFeedItem `json:"items"`
+}
+
+type JSONFeedItem struct {
+	ID            string     `json:"id"`
+	URL           string     `json:"url,omitempty"`
+	Title         string     `json:"title,omitempty"`
+	Summary       string     `json:"summary,omitempty"`
+	ContentHTML   string     `json:"content_html"`
+	Image         string     `json:"image,omitempty"`
+	DatePublished *time.Time `json:"date_published,omitempty"`
+	DateModified  *time.Time `json:"date_modified,omitempty"`
+	Tags          []string   `json:"tags,omitempty"`
+}
+
+// NewJSONFeed - Build one JSON Feed from already loaded blog posts
+func NewJSONFeed(blog *BlogModel, feedURL string, records []*BlogPostModel) *JSONFeed {
+	cfg := catu.GetConfiguration()
+
+	feed := JSONFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       cfg.Get("SITE_NAME"),
+		HomePageURL: cfg.Get("APP_ORIGIN") + "/blogs",
+		FeedURL:     feedURL,
+		Description: cfg.Get("SITE_DESCRIPTION"),
+		Language:    cfg.GetF("SITE_LANGUAGE", "pt-BR"),
+		Items:       []*JSONFeedItem{},
+	}
+
+	if blog != nil {
+		feed.Title = blog.Title
+		feed.HomePageURL = blog.LinkPermanent
+		feed.Description = blog.Description
+	}
+
+	for _, r := range records {
+		updatedAt := r.UpdatedAt
+
+		item := JSONFeedItem{
+			ID:            r.GetIDString(),
+			URL:           r.LinkPermanent,
+			Title:         r.Title,
+			Summary:       r.Teaser,
+			ContentHTML:   string(r.GetBodyHTML()),
+			DatePublished: r.PublishedAt,
+			DateModified:  &updatedAt,
+			Tags:          r.Tags,
+		}
+
+		if image := getFeedImage(r); image != nil {
+			item.Image = image.URLs["original"]
+		}
+
+		feed.Items = append(feed.Items, &item)
+	}
+
+	return &feed
+}
@@ -0,0 +1,204 @@
+package blog
+
+import (
+	"encoding/xml"
+	"net/http"
+	"strconv"
+
+	"github.com/go-catupiry/catu"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Http blog feed controller | struct with http handlers for RSS, Atom and JSON feeds
+type BlogFeedController struct {
+	App catu.App
+}
+
+func (ctl *BlogFeedController) RSS(c echo.Context) error {
+	data, err := ctl.loadFeedData(c)
+	if err != nil {
+		return err
+	}
+
+	return renderFeedXML(c, "application/rss+xml; charset=utf-8", data.ToRSS())
+}
+
+func (ctl *BlogFeedController) Atom(c echo.Context) error {
+	data, err := ctl.loadFeedData(c)
+	if err != nil {
+		return err
+	}
+
+	return renderFeedXML(c, "application/atom+xml; charset=utf-8", data.ToAtom())
+}
+
+// JSONFeed - JSON Feed 1.1 handler, supports paging with ?limit and ?offset query params
+func (ctl *BlogFeedController) JSONFeed(c echo.Context) error {
+	var err error
+	ctx := c.(*catu.RequestContext)
+
+	blog, err := findFeedBlog(c)
+	if err != nil {
+		return err
+	}
+
+	var blogID int64
+	if blog != nil {
+		blogID = int64(blog.ID)
+	}
+
+	limit := ctx.GetLimit()
+	offset := catu.GetQueryIntFromReq("offset", c)
+	if offset < 0 {
+		offset = 0
+	}
+
+	var count int64
+	var records []*BlogPostModel
+	// query one more record to check if we have a next page:
+	err = BlogPostQueryAndCountReq(&BlogPostQueryOpts{
+		BlogID:        blogID,
+		Records:       &records,
+		Count:         &count,
+		Limit:         limit + 1,
+		Offset:        offset,
+		C:             c,
+		IsHTML:        true,
+		OnlyPublished: true,
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"blogId": blogID,
+			"error":  err,
+		}).Error("BlogFeedController.JSONFeed error on find records")
+		return errors.Wrap(err, "error on find feed records")
+	}
+
+	hasNextPage := len(records) > limit
+	if hasNextPage {
+		records = records[:limit]
+	}
+
+	preloadBlogPostsBlogSlugs(records)
+
+	for i := range records {
+		records[i].LoadFeedData()
+	}
+
+	feedURL := ctx.AppOrigin + c.Request().URL.Path
+	feed := NewJSONFeed(blog, feedURL, records)
+
+	if hasNextPage {
+		query := c.QueryParams()
+		query.Set("limit", strconv.Itoa(limit))
+		query.Set("offset", strconv.Itoa(offset+limit))
+		feed.NextURL = feedURL + "?" + query.Encode()
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, "application/feed+json; charset=utf-8")
+	return c.JSON(http.StatusOK, feed)
+}
+
+// Sitemap - Blogs and published posts sitemap, with one sitemap index if the urls don't fit in one file.
+// Index pages are loaded with ?page=N
+func (ctl *BlogFeedController) Sitemap(c echo.Context) error {
+	ctx := c.(*catu.RequestContext)
+
+	count, err := BlogSitemapCount()
+	if err != nil {
+		return errors.Wrap(err, "error on count sitemap urls")
+	}
+
+	pages := int((count + sitemapURLLimit - 1) / sitemapURLLimit)
+
+	page := 1
+	if c.QueryParam("page") != "" {
+		page, err = strconv.Atoi(c.QueryParam("page"))
+		if err != nil || page < 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid page")
+		}
+
+		if page > pages && page > 1 {
+			return echo.NewHTTPError(http.StatusNotFound, "sitemap page not found")
+		}
+	} else if pages > 1 {
+		sitemapURL := ctx.AppOrigin + c.Request().URL.Path
+		return renderFeedXML(c, "application/xml; charset=utf-8", NewSitemapIndex(sitemapURL, count))
+	}
+
+	sitemap, err := NewBlogSitemap(page)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"page":  page,
+			"error": err,
+		}).Error("BlogFeedController.Sitemap error on load sitemap")
+		return errors.Wrap(err, "error on load sitemap")
+	}
+
+	return renderFeedXML(c, "application/xml; charset=utf-8", sitemap)
+}
+
+func (ctl *BlogFeedController) loadFeedData(c echo.Context) (*BlogFeedData, error) {
+	blog, err := findFeedBlog(c)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := NewBlogFeedData(blog, c.Request().URL.Path)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"blogId": c.Param("blogId"),
+			"error":  err,
+		}).Error("BlogFeedController error on load feed data")
+		return nil, errors.Wrap(err, "error on load feed data")
+	}
+
+	return data, nil
+}
+
+// findFeedBlog - Find the blog from blogId route param, returns nil for site wide feeds
+func findFeedBlog(c echo.Context) (*BlogModel, error) {
+	blogId := c.Param("blogId")
+	if blogId == "" {
+		return nil, nil
+	}
+
+	var blog BlogModel
+	err := BlogFindOne(blogId, &blog)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return nil, errors.Wrap(err, "error on find blog") // unknow error
+		}
+		return nil, &catu.HTTPError{
+			Code:     404,
+			Message:  "blog not found",
+			Internal: err,
+		}
+	}
+
+	blog.LoadPath()
+
+	return &blog, nil
+}
+
+func renderFeedXML(c echo.Context, contentType string, feed interface{}) error {
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "error on render feed xml")
+	}
+
+	return c.Blob(http.StatusOK, contentType, append([]byte(xml.Header), body...))
+}
+
+type BlogFeedControllerCfg struct {
+	App catu.App
+}
+
+func NewBlogFeedController(cfg *BlogFeedControllerCfg) *BlogFeedController {
+	ctx := BlogFeedController{App: cfg.App}
+
+	return &ctx
+}
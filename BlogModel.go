@@ -13,7 +13,6 @@ import (
 	"github.com/go-catupiry/files"
 	"github.com/go-catupiry/tags"
 	"github.com/go-catupiry/user"
-	"github.com/gosimple/slug"
 	"github.com/labstack/echo/v4"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
@@ -33,6 +32,7 @@ type BlogModel struct {
 	CreatedAt        time.Time `gorm:"column:createdAt;type:datetime;not null" json:"createdAt"`
 	UpdatedAt        time.Time `gorm:"column:updatedAt;type:datetime;not null" json:"updatedAt"`
 	CreatorID        *int64    `gorm:"index:creatorId;column:creatorId;type:int(11)" json:"creatorId,string"`
+	UpdatedByID      *uint64   `gorm:"index:updatedById;column:updatedById;type:int(11)" json:"updatedById,string"`
 
 	ShowInLists bool `gorm:"column:show_in_lists;"  json:"showInLists" filter:"param:showInLists;type:bool"`
 
@@ -76,16 +76,6 @@ func (r *BlogModel) LoadData() error {
 	return nil
 }
 
-func (r *BlogModel) GetPath() string {
-	path := ""
-
-	if r.ID != 0 {
-		path += "/blog/" + strconv.FormatUint(r.ID, 10)
-	}
-
-	return path
-}
-
 func (r *BlogModel) LoadPath() error {
 	app := catu.GetApp()
 	r.LinkPermanent = app.GetConfiguration().Get("APP_ORIGIN") + r.GetPath()
@@ -101,9 +91,23 @@ func (m *BlogModel) Save() error {
 	var err error
 	db := catu.GetDefaultDatabaseConnection()
 
-	m.RefreshSlug()
+	isNew := m.ID == 0
+	oldSlug := ""
+	if !isNew {
+		var old BlogModel
+		err = db.Omit("Editors").Select("id", "urlUniquePath").Where("id = ?", m.ID).Find(&old).Error
+		if err != nil {
+			return err
+		}
+		oldSlug = old.URLUniquePath
+	}
+
+	err = m.RefreshSlug()
+	if err != nil {
+		return err
+	}
 
-	if m.ID == 0 {
+	if isNew {
 		// create ....
 		err = db.Create(&m).Error
 		if err != nil {
@@ -129,11 +133,88 @@ func (m *BlogModel) Save() error {
 		}).Error("BlogModel.Save error on update logo")
 	}
 
+	err = saveTags(tagsFieldCfg, m.GetIDString(), m.Tags)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"err": err,
+			"id":  m.ID,
+		}).Error("BlogModel.Save error on update tags")
+	}
+
+	err = m.refreshAliasOnSave(isNew || oldSlug != m.URLUniquePath)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"err": err,
+			"id":  m.ID,
+		}).Error("BlogModel.Save error on upsert url alias")
+	}
+
+	if oldSlug != "" && oldSlug != m.URLUniquePath {
+		// posts aliases starts with the blog slug
+		err = m.RefreshPostsAliases()
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"err": err,
+				"id":  m.ID,
+			}).Error("BlogModel.Save error on refresh posts url aliases")
+		}
+	}
+
+	indexBlog(m)
+
+	return nil
+}
+
+// RefreshSlug - Generate the blog slug from title if it is empty, the slug is unique between all blogs
+func (r *BlogModel) RefreshSlug() error {
+	source := r.URLUniquePath
+	if source == "" {
+		source = r.Title
+	}
+
+	s, err := uniqueSlug(makeSlug(source, "blog"), func(s string) (bool, error) {
+		return blogSlugExists(s, r.ID)
+	})
+	if err != nil {
+		return err
+	}
+
+	r.URLUniquePath = s
+
 	return nil
 }
 
-func (r *BlogModel) RefreshSlug() {
-	r.URLUniquePath = helpers.TruncateString(r.URLUniquePath, 60, "")
+// RefreshPostsAliases - Update the url alias of all blog posts, used after the blog slug changes
+func (r *BlogModel) RefreshPostsAliases() error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	var lastID uint64
+	for {
+		records := []*BlogPostModel{}
+		err := db.
+			Where("blogId = ? AND id > ?", r.ID, lastID).
+			Order("id ASC").
+			Limit(100).
+			Find(&records).Error
+		if err != nil {
+			return err
+		}
+
+		for _, post := range records {
+			lastID = post.ID
+
+			err = post.UrlAliasUpsert(r)
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(records) < 100 {
+			break
+		}
+	}
+
+	return nil
 }
 
 func (r *BlogModel) RefreshTerms() error {
@@ -154,6 +235,7 @@ func (r *BlogModel) RefreshTerms() error {
 		return err
 	}
 
+	r.Tags = []string{}
 	for i := range tags {
 		r.Tags = append(r.Tags, tags[i].Text)
 	}
@@ -172,7 +254,7 @@ func (r *BlogModel) LoadImages() error {
 		"id": r.ID,
 	}).Debug("content.LoadImages will refresh")
 
-	logo, err := files.GetImagesInField("blog", "logo", strconv.FormatUint(r.ID, 10), 1)
+	logo, err := files.GetImagesInField(logoFieldCfg.GetModelName(), logoFieldCfg.GetFieldName(), strconv.FormatUint(r.ID, 10), 1)
 	if err != nil {
 		logrus.WithFields(logrus.Fields{
 			"id":         r.ID,
@@ -203,7 +285,39 @@ func (r *BlogModel) LoadImages() error {
 
 func (r *BlogModel) Delete() error {
 	db := catu.GetDefaultDatabaseConnection()
-	return db.Unscoped().Delete(&r).Error
+
+	err := db.Unscoped().Delete(&r).Error
+	if err != nil {
+		return err
+	}
+
+	err = deleteBlogURLAlias(r.GetTargetPath())
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"err": err,
+			"id":  r.ID,
+		}).Error("BlogModel.Delete error on delete url alias")
+	}
+
+	removeBlogFromIndex(r.ID)
+
+	err = clearTags(tagsFieldCfg, r.GetIDString())
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"err": err,
+			"id":  r.ID,
+		}).Error("BlogModel.Delete error on clear tags")
+	}
+
+	err = clearImages(logoFieldCfg, r.GetIDString())
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"err": err,
+			"id":  r.ID,
+		}).Error("BlogModel.Delete error on clear logo")
+	}
+
+	return nil
 }
 
 func (r *BlogModel) LoadLatestPost(limit int) error {
@@ -274,22 +388,20 @@ func BlogQueryAndCountReq(opts *BlogQueryOpts) error {
 	}
 	query = queryI.(*gorm.DB)
 
-	if q != "" {
-		query = query.Where(
-			db.Where("title LIKE ?", "%"+q+"%").Or(db.Where("description LIKE ?", "%"+q+"%")),
-		)
-	}
-
 	// query = query.Where("published = ?", "1")
 
 	orderColumn, orderIsDesc, orderValid := helpers.ParseUrlQueryOrder(c.QueryParam("order"))
 
+	if q != "" {
+		query = setBlogSearchQuery(query, q, !orderValid)
+	}
+
 	if orderValid {
 		query = query.Order(clause.OrderByColumn{
 			Column: clause.Column{Table: clause.CurrentTable, Name: orderColumn},
 			Desc:   orderIsDesc,
 		})
-	} else {
+	} else if q == "" || !isLocalSearchEnabled() {
 		query = query.Order("createdAt DESC").
 			Order("id DESC")
 	}
@@ -307,10 +419,7 @@ func BlogQueryAndCountReq(opts *BlogQueryOpts) error {
 	// Count ...
 	queryCount := db
 	if q != "" {
-		queryCount = queryCount.Or(
-			db.Where("title LIKE ?", "%"+q+"%"),
-			db.Where("description LIKE ?", "%"+q+"%"),
-		)
+		queryCount = setBlogSearchQuery(queryCount, q, false)
 	}
 
 	return queryCount.
@@ -332,10 +441,7 @@ func BlogCountReq(opts *BlogQueryOpts) error {
 	queryCount := db
 
 	if q != "" {
-		queryCount = queryCount.Or(
-			db.Where("title LIKE ?", "%"+q+"%"),
-			db.Where("description LIKE ?", "%"+q+"%"),
-		)
+		queryCount = setBlogSearchQuery(queryCount, q, false)
 	}
 
 	queryICount, err := ctx.Query.SetDatabaseQueryForModel(queryCount, &BlogModel{})
@@ -378,31 +484,47 @@ func getHiClass(isHighlighted bool) string {
 	return ""
 }
 
+// UrlAliasUpsert - Set the /blogs/<slug> alias, or the SetAlias value, for the blog
 func (r *BlogModel) UrlAliasUpsert() error {
 	alias := ""
-	idString := strconv.FormatUint(r.ID, 10)
-	slug.MaxLength = 45
 
 	if r.SetAlias != "" {
 		alias = r.SetAlias
-
 	} else {
-
 		alias = "/blogs/" + r.URLUniquePath
 	}
 
-	target := "/blogs/" + idString
-
-	var aliasRecord drouter.UrlAliasModel
-	err := drouter.URLAliasUpsert(alias, target, "", &aliasRecord)
+	aliasRecord, err := upsertBlogURLAlias(alias, r.GetTargetPath())
 	if err != nil {
 		return err
 	}
-	r.Alias = &aliasRecord
+	r.Alias = aliasRecord
 
 	return nil
 }
 
+// refreshAliasOnSave - Upsert the alias only if it is new, changed or missing. Keeps the custom aliases
+func (r *BlogModel) refreshAliasOnSave(slugChanged bool) error {
+	if !slugChanged && r.SetAlias == "" {
+		r.Alias = nil
+		err := r.LoadAlias()
+		if err != nil {
+			return err
+		}
+
+		if r.Alias != nil {
+			return nil
+		}
+	}
+
+	return r.UrlAliasUpsert()
+}
+
+// GetTargetPath - Route path with the blog id, used as the url alias target
+func (r *BlogModel) GetTargetPath() string {
+	return "/blogs/" + r.GetIDString()
+}
+
 func NewBlogModel() *BlogModel {
 	return &BlogModel{
 		// Published:   false,
@@ -1,17 +1,27 @@
 package blog
 
 import (
+	"fmt"
+
 	"github.com/go-catupiry/catu"
+	"github.com/go-catupiry/files"
 	"github.com/go-catupiry/tags"
 	"github.com/gookit/event"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
 )
 
 type BlogPlugin struct {
 	catu.Pluginer
-	Name               string
-	BlogController     *BlogController
-	BlogPostController *BlogPostController
+	Name                       string
+	BlogController             *BlogController
+	BlogPostController         *BlogPostController
+	BlogFeedController         *BlogFeedController
+	BlogEditorController       *BlogEditorController
+	BlogPostRevisionController *BlogPostRevisionController
+	BlogCommentController      *BlogCommentController
+	BlogAuditLogController     *BlogAuditLogController
 }
 
 func (r *BlogPlugin) GetName() string {
@@ -23,6 +33,11 @@ func (r *BlogPlugin) Init(app catu.App) error {
 
 	r.BlogController = NewBlogController(&BlogControllerCfg{App: app})
 	r.BlogPostController = NewBlogPostController(&BlogPostControllerCfg{App: app})
+	r.BlogFeedController = NewBlogFeedController(&BlogFeedControllerCfg{App: app})
+	r.BlogEditorController = NewBlogEditorController(&BlogEditorControllerCfg{App: app})
+	r.BlogPostRevisionController = NewBlogPostRevisionController(&BlogPostRevisionControllerCfg{App: app})
+	r.BlogCommentController = NewBlogCommentController(&BlogCommentControllerCfg{App: app})
+	r.BlogAuditLogController = NewBlogAuditLogController(&BlogAuditLogControllerCfg{App: app})
 
 	app.GetEvents().On("bindRoutes", event.ListenerFunc(func(e event.Event) error {
 		return r.BindRoutes(app)
@@ -32,8 +47,33 @@ func (r *BlogPlugin) Init(app catu.App) error {
 		return r.Bootstrap(app)
 	}), event.Normal)
 
+	app.GetEvents().On("migrate", event.ListenerFunc(func(e event.Event) error {
+		return r.Migrate(app)
+	}), event.Normal)
+
 	app.GetEvents().On("cron-job", event.ListenerFunc(func(e event.Event) error {
-		return PublishSchenduledBlogPosts(app)
+		err := FlushBlogPostViews()
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"error": fmt.Sprintf("%+v\n", err),
+			}).Error("BlogPlugin cron-job error on flush blog post views")
+		}
+
+		err = PublishSchenduledBlogPosts(app)
+		if err != nil {
+			return err
+		}
+
+		return UnPublishExpiredBlogPosts(app)
+	}), event.Normal)
+
+	app.GetEvents().On("close", event.ListenerFunc(func(e event.Event) error {
+		return FlushBlogPostViews()
+	}), event.Normal)
+
+	// fire this event to rebuild the local search index, like from one app command
+	app.GetEvents().On("blog-search-index-rebuild", event.ListenerFunc(func(e event.Event) error {
+		return RebuildLocalSearchIndex()
 	}), event.Normal)
 
 	return nil
@@ -44,23 +84,69 @@ func (r *BlogPlugin) BindRoutes(app catu.App) error {
 
 	blogCTL := r.BlogController
 	blogPostCTL := r.BlogPostController
+	feedCTL := r.BlogFeedController
+	editorCTL := r.BlogEditorController
+	revisionCTL := r.BlogPostRevisionController
+	commentCTL := r.BlogCommentController
+	auditCTL := r.BlogAuditLogController
 
 	router := app.SetRouterGroup("blogs", "/blogs")
 	router.GET("", blogCTL.FindAllPageHandler)
+	router.GET("/rss.xml", feedCTL.RSS)
+	router.GET("/atom.xml", feedCTL.Atom)
+	router.GET("/feed.json", feedCTL.JSONFeed)
+	router.GET("/sitemap.xml", feedCTL.Sitemap)
+	router.GET("/tags/:tag", blogPostCTL.FindAllByTagPageHandler)
+	router.GET("/authors/:userId", blogPostCTL.FindAllByAuthorPageHandler)
 	router.GET("/:blogId", blogPostCTL.FindAllPageHandler)
+	router.GET("/:blogId/rss.xml", feedCTL.RSS)
+	router.GET("/:blogId/atom.xml", feedCTL.Atom)
+	router.GET("/:blogId/feed.json", feedCTL.JSONFeed)
 	router.GET("/:blogId/:blogPostId", blogPostCTL.FindOnePageHandler)
 
 	routerApi := app.SetRouterGroup("blog-api", "/api/blog")
 	app.SetResource("blog", blogCTL, routerApi)
+	routerApi.GET("/:id/tag-cloud", blogCTL.TagCloud)
+	routerApi.GET("/:id/editors", editorCTL.Query)
+	routerApi.POST("/:id/editors", editorCTL.Create)
+	routerApi.POST("/:id/editors/:userId", editorCTL.Update)
+	routerApi.PATCH("/:id/editors/:userId", editorCTL.Update)
+	routerApi.PUT("/:id/editors/:userId", editorCTL.Update)
+	routerApi.DELETE("/:id/editors/:userId", editorCTL.Delete)
 
 	routerPostApi := app.SetRouterGroup("blog-post-api", "/api/blog-post")
 	app.SetResource("blog-post", blogPostCTL, routerPostApi)
+	routerPostApi.POST("/search-index/rebuild", blogPostCTL.RebuildSearchIndex)
+	routerPostApi.GET("/most-read", blogPostCTL.MostRead)
+	routerPostApi.GET("/:id/transitions", blogPostCTL.Transitions)
+	routerPostApi.POST("/:id/transition", blogPostCTL.Transition)
+	routerPostApi.GET("/:id/revisions", revisionCTL.Query)
+	routerPostApi.GET("/:id/revisions/diff", revisionCTL.Diff)
+	routerPostApi.GET("/:id/revisions/:revisionId", revisionCTL.FindOne)
+	routerPostApi.POST("/:id/revisions/:revisionId/restore", revisionCTL.Restore)
+	routerPostApi.GET("/:id/comments", commentCTL.Query)
+	routerPostApi.POST("/:id/comments", commentCTL.Create)
+	routerPostApi.GET("/:id/comments/form-token", commentCTL.FormToken)
+	routerPostApi.GET("/:id/comments/:commentId", commentCTL.FindOne)
+	routerPostApi.POST("/:id/comments/:commentId", commentCTL.Update)
+	routerPostApi.PATCH("/:id/comments/:commentId", commentCTL.Update)
+	routerPostApi.PUT("/:id/comments/:commentId", commentCTL.Update)
+	routerPostApi.DELETE("/:id/comments/:commentId", commentCTL.Delete)
+
+	routerAuditApi := app.SetRouterGroup("blog-audit-log-api", "/api/blog-audit-log")
+	routerAuditApi.GET("", auditCTL.Query)
 
 	return nil
 }
 
 func (r *BlogPlugin) Bootstrap(app catu.App) error {
-	tagsFieldCfg = tags.NewTagFieldConfiguration("Tags", "blog", "tags")
+	tagsFieldCfg = tags.NewTagFieldConfiguration(blogTagsVocabulary, "blog", "tags")
+	blogPostTagsFieldCfg = tags.NewTagFieldConfiguration(blogTagsVocabulary, "blog-post", "tags")
+	logoFieldCfg = files.NewImageFieldConfiguration("blog", "logo")
+	featuredImageFieldCfg = files.NewImageFieldConfiguration("blog-post", "featuredImage")
+	galleryFieldCfg = files.NewImageFieldConfiguration("blog-post", "gallery")
+	galleryFieldCfg.SetFormFieldMultiple(true)
+	attachmentsFieldCfg = files.NewFileFieldConfiguration("blog-post", "attachments")
 
 	db := app.GetDB()
 
@@ -69,6 +155,220 @@ func (r *BlogPlugin) Bootstrap(app catu.App) error {
 		return err
 	}
 
+	SelectBlogPostSearchBackend(app)
+
+	if r.BlogCommentController != nil && r.BlogCommentController.Guard == nil {
+		r.BlogCommentController.Guard = NewSubmissionGuardFromConfiguration(app.GetConfiguration())
+	}
+
+	if isLocalSearchEnabled() {
+		go func() {
+			err := RebuildLocalSearchIndex()
+			if err != nil {
+				logrus.WithFields(logrus.Fields{
+					"error": fmt.Sprintf("%+v\n", err),
+				}).Error("BlogPlugin.Bootstrap error on build local search index")
+			}
+		}()
+	}
+
+	return nil
+}
+
+// Migrate - Create the plugin tables and columns missing in the database
+func (r *BlogPlugin) Migrate(app catu.App) error {
+	logrus.Debug(r.GetName() + " Migrate")
+
+	db := app.GetDB()
+	migrator := db.Migrator()
+
+	err := db.AutoMigrate(&BlogPostRevisionModel{}, &BlogURLRedirectModel{}, &BlogCommentModel{}, &BlogPostStatModel{}, &BlogPostMediaModel{}, &BlogPostAuthorModel{}, &BlogAuditLogModel{})
+	if err != nil {
+		return errors.Wrap(err, "BlogPlugin.Migrate error on migrate tables")
+	}
+
+	if !migrator.HasColumn(&BlogPostModel{}, "Status") {
+		err = migrator.AddColumn(&BlogPostModel{}, "Status")
+		if err != nil {
+			return errors.Wrap(err, "BlogPlugin.Migrate error on add blog post status column")
+		}
+
+		// set the status of old records from the published fields:
+		err = db.Model(&BlogPostModel{}).
+			Where("published = ?", true).
+			Update("status", BlogPostStatusPublished).Error
+		if err != nil {
+			return errors.Wrap(err, "BlogPlugin.Migrate error on set published status")
+		}
+
+		err = db.Model(&BlogPostModel{}).
+			Where("published = ? AND publishedAt IS NOT NULL", false).
+			Update("status", BlogPostStatusScheduled).Error
+		if err != nil {
+			return errors.Wrap(err, "BlogPlugin.Migrate error on set scheduled status")
+		}
+	}
+
+	if !migrator.HasColumn(&BlogPostModel{}, "UnpublishAt") {
+		err = migrator.AddColumn(&BlogPostModel{}, "UnpublishAt")
+		if err != nil {
+			return errors.Wrap(err, "BlogPlugin.Migrate error on add blog post unpublishAt column")
+		}
+	}
+
+	if !migrator.HasColumn(&BlogPostModel{}, "SearchTags") {
+		err = migrator.AddColumn(&BlogPostModel{}, "SearchTags")
+		if err != nil {
+			return errors.Wrap(err, "BlogPlugin.Migrate error on add blog post searchTags column")
+		}
+	}
+
+	if !migrator.HasColumn(&BlogPostModel{}, "BodyFormat") {
+		err = migrator.AddColumn(&BlogPostModel{}, "BodyFormat")
+		if err != nil {
+			return errors.Wrap(err, "BlogPlugin.Migrate error on add blog post bodyFormat column")
+		}
+	}
+
+	if !migrator.HasColumn(&BlogPostModel{}, "BodyHTML") {
+		err = migrator.AddColumn(&BlogPostModel{}, "BodyHTML")
+		if err != nil {
+			return errors.Wrap(err, "BlogPlugin.Migrate error on add blog post bodyHTML column")
+		}
+	}
+
+	err = migrateBlogPostBodyHTML(db)
+	if err != nil {
+		return err
+	}
+
+	searchBackend := getConfiguredBlogPostSearchBackend(app)
+	err = searchBackend.Setup(db)
+	if err != nil {
+		// the search works without the index, with the like backend
+		logrus.WithFields(logrus.Fields{
+			"backend": searchBackend.Name(),
+			"error":   fmt.Sprintf("%+v\n", err),
+		}).Warn("BlogPlugin.Migrate error on setup search backend")
+	}
+	SelectBlogPostSearchBackend(app)
+
+	err = migrateBlogPostSlugs(db)
+	if err != nil {
+		return err
+	}
+
+	// featured images were associated with the BlogPostModel model name
+	if migrator.HasTable("imageassocs") {
+		err = db.Exec("UPDATE imageassocs SET modelName = ? WHERE modelName = ? AND field = ?", "blog-post", "BlogPostModel", "featuredImage").Error
+		if err != nil {
+			return errors.Wrap(err, "BlogPlugin.Migrate error on update featured image model name")
+		}
+	}
+
+	if !migrator.HasColumn(&BlogPostModel{}, "UpdatedByID") {
+		err = migrator.AddColumn(&BlogPostModel{}, "UpdatedByID")
+		if err != nil {
+			return errors.Wrap(err, "BlogPlugin.Migrate error on add blog post updatedById column")
+		}
+	}
+
+	if !migrator.HasColumn(&BlogModel{}, "UpdatedByID") {
+		err = migrator.AddColumn(&BlogModel{}, "UpdatedByID")
+		if err != nil {
+			return errors.Wrap(err, "BlogPlugin.Migrate error on add blog updatedById column")
+		}
+	}
+
+	if !migrator.HasColumn(&BlogEditorsModel{}, "Role") {
+		err = migrator.AddColumn(&BlogEditorsModel{}, "Role")
+		if err != nil {
+			return errors.Wrap(err, "BlogPlugin.Migrate error on add blog editor role column")
+		}
+	}
+
+	return nil
+}
+
+// migrateBlogPostSlugs - Generate the slug and url alias of old posts without slug
+func migrateBlogPostSlugs(db *gorm.DB) error {
+	for {
+		records := []*BlogPostModel{}
+		err := db.
+			Where("urlPath = ? OR urlPath IS NULL", "").
+			Order("id ASC").
+			Limit(100).
+			Find(&records).Error
+		if err != nil {
+			return errors.Wrap(err, "BlogPlugin.Migrate error on find posts without slug")
+		}
+
+		for _, r := range records {
+			err = r.RefreshSlug()
+			if err != nil {
+				return errors.Wrap(err, "BlogPlugin.Migrate error on refresh post slug")
+			}
+
+			err = db.Model(r).Update("urlPath", r.URLPath).Error
+			if err != nil {
+				return errors.Wrap(err, "BlogPlugin.Migrate error on save post slug")
+			}
+
+			err = r.UrlAliasUpsert(nil)
+			if err != nil {
+				logrus.WithFields(logrus.Fields{
+					"id":    r.ID,
+					"error": fmt.Sprintf("%+v\n", err),
+				}).Warn("BlogPlugin.Migrate error on set post url alias")
+			}
+		}
+
+		if len(records) < 100 {
+			break
+		}
+	}
+
+	return nil
+}
+
+// migrateBlogPostBodyHTML - Render the body html cache of the posts created before the bodyHTML column
+func migrateBlogPostBodyHTML(db *gorm.DB) error {
+	var lastID uint64
+
+	for {
+		records := []*BlogPostModel{}
+		err := db.
+			Select("id", "body", "bodyFormat").
+			Where("bodyHTML IS NULL AND id > ?", lastID).
+			Order("id ASC").
+			Limit(100).
+			Find(&records).Error
+		if err != nil {
+			return errors.Wrap(err, "BlogPlugin.Migrate error on find posts without body html")
+		}
+
+		for _, r := range records {
+			lastID = r.ID
+
+			err = r.RenderBody()
+			if err != nil {
+				return errors.Wrap(err, "BlogPlugin.Migrate error on render post body")
+			}
+
+			err = db.Model(r).UpdateColumns(map[string]interface{}{
+				"bodyFormat": r.BodyFormat,
+				"bodyHTML":   r.BodyHTML,
+			}).Error
+			if err != nil {
+				return errors.Wrap(err, "BlogPlugin.Migrate error on save post body html")
+			}
+		}
+
+		if len(records) < 100 {
+			break
+		}
+	}
+
 	return nil
 }
 
@@ -0,0 +1,220 @@
+package blog
+
+import (
+	"strconv"
+
+	"github.com/go-catupiry/catu"
+	"github.com/go-catupiry/user"
+	"gorm.io/gorm"
+)
+
+// BlogPostAuthorModel Stores the post authors and the byline order
+type BlogPostAuthorModel struct {
+	ID         uint64 `gorm:"primaryKey;column:id" json:"id"`
+	BlogPostID uint64 `gorm:"uniqueIndex:blogPostAuthor;column:blogPostId;not null" json:"blogPostId"`
+	UserID     uint64 `gorm:"uniqueIndex:blogPostAuthor;index:blogPostAuthorUser;column:userId;not null" json:"userId"`
+	Order      int    `gorm:"column:order;not null;default:0" json:"order"`
+
+	User *user.UserModel `gorm:"-" json:"user"`
+}
+
+// TableName get sql table name
+func (m *BlogPostAuthorModel) TableName() string {
+	return "blog_post_authors"
+}
+
+// BlogPostAuthorItem - One post author in the byline. ID is the user id
+type BlogPostAuthorItem struct {
+	ID            uint64 `json:"id"`
+	Order         int    `json:"order"`
+	DisplayName   string `json:"displayName"`
+	AvatarURL     string `json:"avatarUrl"`
+	LinkPermanent string `json:"linkPermanent"`
+}
+
+// NewBlogPostAuthorItem - Byline item with the user name, avatar and author page urls
+func NewBlogPostAuthorItem(u *user.UserModel, order int) *BlogPostAuthorItem {
+	origin := catu.GetConfiguration().Get("APP_ORIGIN")
+	id := strconv.FormatUint(u.ID, 10)
+
+	return &BlogPostAuthorItem{
+		ID:            u.ID,
+		Order:         order,
+		DisplayName:   u.DisplayName,
+		AvatarURL:     origin + "/avatar/" + id,
+		LinkPermanent: origin + GetBlogAuthorPath(u.ID),
+	}
+}
+
+// GetBlogAuthorPath - Author archive page path
+func GetBlogAuthorPath(userID uint64) string {
+	return "/blogs/authors/" + strconv.FormatUint(userID, 10)
+}
+
+// LoadAuthors - Load the post authors in the byline order. Posts without authors use the creator
+func (r *BlogPostModel) LoadAuthors() error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	r.Authors = []*BlogPostAuthorItem{}
+
+	if r.ID == 0 {
+		return nil
+	}
+
+	records := []*BlogPostAuthorModel{}
+	err := db.
+		Where("blogPostId = ?", r.ID).
+		Order("`order` ASC").
+		Order("id ASC").
+		Find(&records).Error
+	if err != nil {
+		return err
+	}
+
+	ids := []uint64{}
+	for _, a := range records {
+		ids = append(ids, a.UserID)
+	}
+
+	if len(ids) == 0 && r.CreatorID != nil {
+		ids = append(ids, uint64(*r.CreatorID))
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	users, err := findBlogUsersByID(ids)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if u, ok := users[id]; ok {
+			r.Authors = append(r.Authors, NewBlogPostAuthorItem(u, len(r.Authors)))
+		}
+	}
+
+	return nil
+}
+
+// SaveAuthors - Replace the post authors, nil lists are not changed. The list position is the byline order
+func (r *BlogPostModel) SaveAuthors() error {
+	if r.Authors == nil {
+		return nil
+	}
+
+	ids := []uint64{}
+	seen := map[uint64]bool{}
+	for _, a := range r.Authors {
+		if a == nil || a.ID == 0 || seen[a.ID] {
+			continue
+		}
+
+		seen[a.ID] = true
+		ids = append(ids, a.ID)
+	}
+
+	users, err := findBlogUsersByID(ids)
+	if err != nil {
+		return err
+	}
+
+	db := catu.GetDefaultDatabaseConnection()
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := tx.
+			Where("blogPostId = ?", r.ID).
+			Delete(&BlogPostAuthorModel{}).Error
+		if err != nil {
+			return err
+		}
+
+		records := []*BlogPostAuthorModel{}
+		for _, id := range ids {
+			// skip deleted or unknown users
+			if _, ok := users[id]; !ok {
+				continue
+			}
+
+			records = append(records, &BlogPostAuthorModel{
+				BlogPostID: r.ID,
+				UserID:     id,
+				Order:      len(records),
+			})
+		}
+
+		if len(records) == 0 {
+			return nil
+		}
+
+		return tx.Create(&records).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	return r.LoadAuthors()
+}
+
+// ClearAuthors - Remove all authors from the post
+func (r *BlogPostModel) ClearAuthors() error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	return db.
+		Where("blogPostId = ?", r.ID).
+		Delete(&BlogPostAuthorModel{}).Error
+}
+
+// GetAuthorNames - Author display names in the byline order
+func (r *BlogPostModel) GetAuthorNames() []string {
+	names := []string{}
+	for _, a := range r.Authors {
+		names = append(names, a.DisplayName)
+	}
+
+	return names
+}
+
+// findBlogUsersByID - Users by id, missing users are not in the result
+func findBlogUsersByID(ids []uint64) (map[uint64]*user.UserModel, error) {
+	result := map[uint64]*user.UserModel{}
+
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	db := catu.GetDefaultDatabaseConnection()
+
+	users := []*user.UserModel{}
+	err := db.Where("id IN ?", ids).Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, u := range users {
+		result[u.ID] = u
+	}
+
+	return result, nil
+}
+
+// BlogAuthorFindOne - Find the author user
+func BlogAuthorFindOne(userID string, record *user.UserModel) error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	return db.
+		Where("id = ?", userID).
+		First(record).Error
+}
+
+// blogPostAuthorSubQuery - Ids of the posts with the author, posts without authors are matched by the creator
+func blogPostAuthorSubQuery(db *gorm.DB, userID string) *gorm.DB {
+	return db.
+		Model(&BlogPostModel{}).
+		Select("id").
+		Where(
+			db.Where("id IN (?)", db.Model(&BlogPostAuthorModel{}).Select("blogPostId").Where("userId = ?", userID)).
+				Or("creatorId = ? AND id NOT IN (?)", userID, db.Model(&BlogPostAuthorModel{}).Select("blogPostId")),
+		)
+}
@@ -0,0 +1,96 @@
+package blog
+
+import (
+	"bytes"
+	"html/template"
+	"regexp"
+	"sync"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/sirupsen/logrus"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// Blog post body formats
+const (
+	BlogPostBodyFormatHTML     = "html"
+	BlogPostBodyFormatMarkdown = "markdown"
+)
+
+var bodyMarkdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	// raw html is removed by the sanitizer
+	goldmark.WithRendererOptions(html.WithUnsafe()),
+)
+
+var bodyPolicyOnce sync.Once
+var bodyPolicy *bluemonday.Policy
+
+// bodySanitizerPolicy - User generated content policy with code block languages
+func bodySanitizerPolicy() *bluemonday.Policy {
+	bodyPolicyOnce.Do(func() {
+		bodyPolicy = bluemonday.UGCPolicy()
+		bodyPolicy.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w-]+$`)).OnElements("code")
+	})
+
+	return bodyPolicy
+}
+
+func IsValidBlogPostBodyFormat(format string) bool {
+	switch format {
+	case BlogPostBodyFormatHTML, BlogPostBodyFormatMarkdown:
+		return true
+	}
+
+	return false
+}
+
+// RenderBlogPostBody - Convert the body to HTML if needed and sanitize it
+func RenderBlogPostBody(body, format string) (string, error) {
+	source := []byte(body)
+
+	if format == BlogPostBodyFormatMarkdown {
+		var buf bytes.Buffer
+		err := bodyMarkdown.Convert(source, &buf)
+		if err != nil {
+			return "", err
+		}
+		source = buf.Bytes()
+	}
+
+	return string(bodySanitizerPolicy().SanitizeBytes(source)), nil
+}
+
+// RenderBody - Render the body in the BodyHTML cache
+func (r *BlogPostModel) RenderBody() error {
+	if r.BodyFormat == "" {
+		r.BodyFormat = BlogPostBodyFormatHTML
+	}
+
+	bodyHTML, err := RenderBlogPostBody(r.Body, r.BodyFormat)
+	if err != nil {
+		return err
+	}
+
+	r.BodyHTML = bodyHTML
+
+	return nil
+}
+
+// GetBodyHTML - Sanitized body HTML for templates, rendered if the cache is empty
+func (r *BlogPostModel) GetBodyHTML() template.HTML {
+	if r.BodyHTML == "" && r.Body != "" {
+		err := r.RenderBody()
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"id":    r.ID,
+				"error": err,
+			}).Error("BlogPostModel.GetBodyHTML error on render body")
+			return ""
+		}
+	}
+
+	return template.HTML(r.BodyHTML)
+}
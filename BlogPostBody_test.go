@@ -0,0 +1,74 @@
+package blog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderBlogPostBodySanitize(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		format string
+	}{
+		{"html script", `<p>hi</p><script>alert("xss")</script>`, BlogPostBodyFormatHTML},
+		{"markdown script", "hi\n\n<script>alert(\"xss\")</script>", BlogPostBodyFormatMarkdown},
+		{"html event attribute", `<p onclick="alert('xss')">hi</p><img src="/a.png" onerror="alert('xss')">`, BlogPostBodyFormatHTML},
+		{"markdown event attribute", "hi <b onmouseover=\"alert('xss')\">bold</b>\n\n<img src=\"/a.png\" onerror=\"alert('xss')\">", BlogPostBodyFormatMarkdown},
+		{"html javascript link", `<a href="javascript:alert('xss')">hi</a>`, BlogPostBodyFormatHTML},
+		{"markdown javascript link", "[hi](javascript:alert('xss'))\n\n<a href=\"JavaScript:alert('xss')\">raw</a>", BlogPostBodyFormatMarkdown},
+	}
+
+	for _, tt := range tests {
+		got, err := RenderBlogPostBody(tt.body, tt.format)
+		if err != nil {
+			t.Fatalf("%s: error on render body: %v", tt.name, err)
+		}
+
+		lower := strings.ToLower(got)
+		for _, unsafe := range []string{"<script", "alert(", "onclick", "onerror", "onmouseover", "javascript:"} {
+			if strings.Contains(lower, unsafe) {
+				t.Errorf("%s: rendered body %q contains %q", tt.name, got, unsafe)
+			}
+		}
+
+		if !strings.Contains(got, "hi") {
+			t.Errorf("%s: rendered body %q lost the safe content", tt.name, got)
+		}
+	}
+}
+
+func TestRenderBlogPostBodyKeepsSafeContent(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		format string
+		want   []string
+	}{
+		{
+			"html",
+			`<p>hi <a href="https://example.com">link</a></p>`,
+			BlogPostBodyFormatHTML,
+			[]string{"<p>hi", `href="https://example.com"`},
+		},
+		{
+			"markdown",
+			"# Title\n\n[link](https://example.com)\n\n```go\nfmt.Println()\n```",
+			BlogPostBodyFormatMarkdown,
+			[]string{"<h1>Title</h1>", `href="https://example.com"`, `<code class="language-go">`},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := RenderBlogPostBody(tt.body, tt.format)
+		if err != nil {
+			t.Fatalf("%s: error on render body: %v", tt.name, err)
+		}
+
+		for _, want := range tt.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s: rendered body %q should contain %q", tt.name, got, want)
+			}
+		}
+	}
+}
@@ -3,8 +3,12 @@ package blog
 import (
 	"bytes"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/go-catupiry/catu"
+	"github.com/go-catupiry/user"
 	"github.com/labstack/echo/v4"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -72,6 +76,8 @@ func (ctl *BlogPostController) Query(c echo.Context) error {
 		"len_records_found": len(records),
 	}).Debug("BlogPostFindAll count result")
 
+	preloadBlogPostsBlogSlugs(records)
+
 	for i := range records {
 		records[i].LoadData()
 	}
@@ -90,11 +96,6 @@ func (ctl *BlogPostController) Create(c echo.Context) error {
 	var err error
 	ctx := c.(*catu.RequestContext)
 
-	can := ctx.Can("create_blog-post")
-	if !can {
-		return echo.NewHTTPError(http.StatusForbidden, "Forbidden")
-	}
-
 	var body BlogPostBodyRequest
 
 	if err := c.Bind(&body); err != nil {
@@ -107,6 +108,19 @@ func (ctl *BlogPostController) Create(c echo.Context) error {
 	record := body.Record
 	record.ID = 0
 
+	can := CanManageBlogPosts(ctx, "create_blog-post", record.BlogID)
+	if !can {
+		return echo.NewHTTPError(http.StatusForbidden, "Forbidden")
+	}
+
+	// new posts always start as draft, use the transition endpoint to change the status
+	record.Status = BlogPostStatusDraft
+	record.Published = false
+
+	if record.BodyFormat != "" && !IsValidBlogPostBodyFormat(record.BodyFormat) {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid bodyFormat")
+	}
+
 	if err := c.Validate(record); err != nil {
 		if _, ok := err.(*echo.HTTPError); ok {
 			return err
@@ -118,11 +132,22 @@ func (ctl *BlogPostController) Create(c echo.Context) error {
 		"body": body,
 	}).Info("BlogPostController.Create params")
 
+	// the creator is always the authenticated user
+	userID := getAuthenticatedUserID(ctx)
+	record.CreatorID = nil
+	if userID != nil {
+		creatorID := uint(*userID)
+		record.CreatorID = &creatorID
+	}
+	record.UpdatedByID = userID
+
 	err = record.Save()
 	if err != nil {
 		return err
 	}
 
+	auditBlogPost(NewBlogAuditActorFromCtx(ctx), BlogAuditActionCreate, record, nil, record.AuditFields())
+
 	err = record.LoadData()
 	if err != nil {
 		return err
@@ -189,7 +214,7 @@ func (ctl *BlogPostController) FindOne(c echo.Context) error {
 	}
 
 	if !record.Published {
-		canAccessUnpublished := ctx.Can("access_contents_unpublished")
+		canAccessUnpublished := CanManageBlogPosts(ctx, "access_contents_unpublished", record.BlogID)
 		if !canAccessUnpublished {
 			return &catu.HTTPError{
 				Code:     403,
@@ -230,13 +255,26 @@ func (ctl *BlogPostController) Update(c echo.Context) error {
 		return errors.Wrap(err, "BlogPostController.Update error on find one")
 	}
 
-	can := RequestContext.Can("update_blog-post")
+	can := CanManageBlogPosts(RequestContext, "update_blog-post", record.BlogID)
 	if !can {
 		return echo.NewHTTPError(http.StatusForbidden, "Forbidden")
 	}
 
 	record.LoadData()
 
+	oldBlogID := record.BlogID
+	oldStatus := record.Status
+	oldPublished := record.Published
+	oldPublishedAt := record.PublishedAt
+	oldCreatorID := record.CreatorID
+	recordID := record.ID
+	before := record.AuditFields()
+
+	// only change the media and authors lists sent in the body
+	record.Gallery = nil
+	record.Attachments = nil
+	record.Authors = nil
+
 	body := BlogPostFindOneJSONResponse{Record: &record}
 
 	if err := c.Bind(&body); err != nil {
@@ -251,10 +289,37 @@ func (ctl *BlogPostController) Update(c echo.Context) error {
 		return c.NoContent(http.StatusNotFound)
 	}
 
+	// the post id is always the one from the url, the body id is ignored
+	record.ID = recordID
+
+	// moving the post to other blog requires access in the new blog too
+	if !sameBlogID(oldBlogID, record.BlogID) {
+		can = CanManageBlogPosts(RequestContext, "update_blog-post", record.BlogID)
+		if !can {
+			return echo.NewHTTPError(http.StatusForbidden, "Forbidden")
+		}
+	}
+
+	if record.BodyFormat != "" && !IsValidBlogPostBodyFormat(record.BodyFormat) {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid bodyFormat")
+	}
+
+	// status changes are only allowed in the transition endpoint
+	record.Status = oldStatus
+	record.Published = oldPublished
+	if record.Status == BlogPostStatusPublished || record.Status == BlogPostStatusScheduled {
+		record.PublishedAt = oldPublishedAt
+	}
+
+	record.CreatorID = oldCreatorID
+	record.UpdatedByID = getAuthenticatedUserID(RequestContext)
+
 	err = record.Save()
 	if err != nil {
 		return err
 	}
+
+	auditBlogPost(NewBlogAuditActorFromCtx(RequestContext), BlogAuditActionUpdate, &record, before, record.AuditFields())
 	resp := BlogPostFindOneJSONResponse{
 		Record: &record,
 	}
@@ -262,6 +327,60 @@ func (ctl *BlogPostController) Update(c echo.Context) error {
 	return c.JSON(http.StatusOK, &resp)
 }
 
+// RebuildSearchIndex - Rebuild the local search index with all blogs and blog posts
+func (ctl *BlogPostController) RebuildSearchIndex(c echo.Context) error {
+	ctx := c.(*catu.RequestContext)
+
+	if !ctx.Can("rebuild_blog_search_index") {
+		return echo.NewHTTPError(http.StatusForbidden, "Forbidden")
+	}
+
+	if !isLocalSearchEnabled() {
+		return echo.NewHTTPError(http.StatusBadRequest, "the local search backend is not enabled")
+	}
+
+	err := RebuildLocalSearchIndex()
+	if err != nil {
+		return errors.Wrap(err, "BlogPostController.RebuildSearchIndex error on rebuild")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// MostRead - Published posts with more views in the last ?days, from all blogs or from the ?blogId blog
+func (ctl *BlogPostController) MostRead(c echo.Context) error {
+	ctx := c.(*catu.RequestContext)
+
+	days := getMostReadDays()
+	if v := c.QueryParam("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > 365 {
+			return echo.NewHTTPError(http.StatusBadRequest, "days must be between 1 and 365")
+		}
+		days = n
+	}
+
+	records := []*BlogPostModel{}
+	err := BlogPostFindMostRead(parseBlogID(c.QueryParam("blogId")), days, ctx.GetLimit(), time.Now(), &records)
+	if err != nil {
+		return errors.Wrap(err, "BlogPostController.MostRead error on find most read posts")
+	}
+
+	preloadBlogPostsBlogSlugs(records)
+
+	for i := range records {
+		records[i].LoadTeaserData()
+	}
+
+	resp := BlogPostJSONResponse{
+		Records: &records,
+	}
+
+	resp.Meta.Count = int64(len(records))
+
+	return c.JSON(http.StatusOK, &resp)
+}
+
 func (ctl *BlogPostController) Delete(c echo.Context) error {
 	var err error
 
@@ -283,19 +402,153 @@ func (ctl *BlogPostController) Delete(c echo.Context) error {
 		return c.JSON(http.StatusNotFound, make(map[string]string))
 	}
 
-	can := RequestContext.Can("delete_blog-post")
+	can := CanManageBlogPosts(RequestContext, "delete_blog-post", record.BlogID)
 	if !can {
 		return echo.NewHTTPError(http.StatusForbidden, "Forbidden")
 	}
 
+	before := record.AuditFields()
+
 	err = record.Delete()
 	if err != nil {
 		return err
 	}
 
+	auditBlogPost(NewBlogAuditActorFromCtx(RequestContext), BlogAuditActionDelete, &record, before, nil)
+
 	return c.NoContent(http.StatusNoContent)
 }
 
+type BlogPostTransitionBodyRequest struct {
+	Status      string     `json:"status"`
+	PublishedAt *time.Time `json:"publishedAt"`
+}
+
+type BlogPostTransitionJSONResponse struct {
+	Record      *BlogPostModel       `json:"blog-post"`
+	Transitions []BlogPostTransition `json:"transitions"`
+}
+
+// Transitions - List the status transitions available for one blog post
+func (ctl *BlogPostController) Transitions(c echo.Context) error {
+	ctx := c.(*catu.RequestContext)
+
+	record, err := findTransitionBlogPost(c)
+	if err != nil {
+		return err
+	}
+
+	transitions := []BlogPostTransition{}
+	available := GetBlogPostTransitionsFrom(record.Status)
+	for i := range available {
+		if CanRunBlogPostTransition(ctx, &available[i], record) {
+			transitions = append(transitions, available[i])
+		}
+	}
+
+	record.LoadData()
+
+	resp := BlogPostTransitionJSONResponse{
+		Record:      record,
+		Transitions: transitions,
+	}
+
+	return c.JSON(http.StatusOK, &resp)
+}
+
+// Transition - Change the blog post editorial status, checking the transition rules and permissions
+func (ctl *BlogPostController) Transition(c echo.Context) error {
+	ctx := c.(*catu.RequestContext)
+
+	record, err := findTransitionBlogPost(c)
+	if err != nil {
+		return err
+	}
+
+	before := record.AuditFields()
+
+	var body BlogPostTransitionBodyRequest
+
+	if err := c.Bind(&body); err != nil {
+		if _, ok := err.(*echo.HTTPError); ok {
+			return err
+		}
+		return c.NoContent(http.StatusNotFound)
+	}
+
+	transition := FindBlogPostTransition(record.Status, body.Status)
+	if transition == nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid status transition from "+record.Status+" to "+body.Status)
+	}
+
+	if !CanRunBlogPostTransition(ctx, transition, record) {
+		return echo.NewHTTPError(http.StatusForbidden, "Forbidden")
+	}
+
+	if body.PublishedAt != nil {
+		record.PublishedAt = body.PublishedAt
+	}
+
+	record.UpdatedByID = getAuthenticatedUserID(ctx)
+
+	err = record.ChangeStatus(transition.To)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"id":    record.ID,
+			"from":  transition.From,
+			"to":    transition.To,
+			"error": err,
+		}).Debug("BlogPostController.Transition error on change status")
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"id":   record.ID,
+		"from": transition.From,
+		"to":   transition.To,
+	}).Info("BlogPostController.Transition blog post status changed")
+
+	action := BlogAuditActionUpdate
+	if transition.To == BlogPostStatusPublished {
+		action = BlogAuditActionPublish
+	} else if transition.From == BlogPostStatusPublished {
+		action = BlogAuditActionUnpublish
+	}
+
+	auditBlogPost(NewBlogAuditActorFromCtx(ctx), action, record, before, record.AuditFields())
+
+	record.LoadData()
+
+	resp := BlogPostFindOneJSONResponse{
+		Record: record,
+	}
+
+	return c.JSON(http.StatusOK, &resp)
+}
+
+func findTransitionBlogPost(c echo.Context) (*BlogPostModel, error) {
+	ctx := c.(*catu.RequestContext)
+
+	var record BlogPostModel
+	err := BlogPostFindOne(c.Param("id"), &record)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return nil, errors.Wrap(err, "error on find blog post") // unknow error
+		}
+		return nil, &catu.HTTPError{
+			Code:     404,
+			Message:  "blog post not found",
+			Internal: err,
+		}
+	}
+
+	if !CanManageBlogPosts(ctx, "update_blog-post", record.BlogID) {
+		return nil, echo.NewHTTPError(http.StatusForbidden, "Forbidden")
+	}
+
+	return &record, nil
+}
+
 func (ctl *BlogPostController) FindAllPageHandler(c echo.Context) error {
 	var err error
 	ctx := c.(*catu.RequestContext)
@@ -307,15 +560,38 @@ func (ctl *BlogPostController) FindAllPageHandler(c echo.Context) error {
 
 	err = loadCtxBlog(ctx)
 	if err != nil {
+		if isNotFoundError(err) {
+			if redirected, rErr := redirectFromOldURL(c); redirected || rErr != nil {
+				return rErr
+			}
+		}
 		return err
 	}
 
 	ctx.Title = "Blogs"
 	ctx.MetaTags.Title = "Blogs do Monitor do Mercado"
 
+	var blogID int64
+	if blog, ok := ctx.Get("blog").(BlogModel); ok {
+		blogID = int64(blog.ID)
+
+		redirected, err := setCanonicalURL(ctx, blog.GetPath())
+		if redirected || err != nil {
+			return err
+		}
+
+		err = LoadBlogPageMetadata(ctx, &blog)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"err": err.Error(),
+			}).Error("BlogPostController.FindAllPageHandler error on load blog metadata")
+		}
+	}
+
 	var count int64
 	var records []*BlogPostModel
 	err = BlogPostQueryAndCountReq(&BlogPostQueryOpts{
+		BlogID:  blogID,
 		Records: &records,
 		Count:   &count,
 		Limit:   ctx.GetLimit(),
@@ -337,6 +613,8 @@ func (ctl *BlogPostController) FindAllPageHandler(c echo.Context) error {
 		"len_records_found": len(records),
 	}).Debug("BlogPostController.FindAllPageHandler count result")
 
+	preloadBlogPostsBlogSlugs(records)
+
 	for i := range records {
 		records[i].LoadTeaserData()
 
@@ -364,12 +642,178 @@ func (ctl *BlogPostController) FindAllPageHandler(c echo.Context) error {
 	ctx.Set("records", teaserList)
 	ctx.Set("RequestPath", ctx.Request().URL.String())
 
-	// err = content.LoadMostReadBlockData(ctx)
-	// if err != nil {
-	// 	logrus.WithFields(logrus.Fields{
-	// 		"err": err.Error(),
-	// 	}).Error("BlogPostController.FindAllPageHandler error on render sidebar block")
-	// }
+	var sidebarBlogID *uint64
+	if blogID != 0 {
+		id := uint64(blogID)
+		sidebarBlogID = &id
+	}
+
+	err = LoadTagCloudBlockData(ctx, sidebarBlogID, blogTagCloudBlockLimit)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"err": err.Error(),
+		}).Error("BlogPostController.FindAllPageHandler error on render sidebar block")
+	}
+
+	err = LoadMostReadBlockData(ctx, sidebarBlogID)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"err": err.Error(),
+		}).Error("BlogPostController.FindAllPageHandler error on render most read block")
+	}
+
+	return c.Render(http.StatusOK, "blog-post/findAll", &catu.TemplateCTX{
+		Ctx: ctx,
+	})
+}
+
+// FindAllByTagPageHandler - Published posts from all blogs with the tag
+func (ctl *BlogPostController) FindAllByTagPageHandler(c echo.Context) error {
+	var err error
+	ctx := c.(*catu.RequestContext)
+
+	switch ctx.GetResponseContentType() {
+	case "application/json":
+		return ctl.Query(c)
+	}
+
+	tag := normalizeTag(c.Param("tag"))
+	if tag == "" {
+		return echo.NewHTTPError(http.StatusNotFound, "tag not found")
+	}
+
+	ctx.Title = tag
+	ctx.MetaTags.Title = tag
+	ctx.MetaTags.Canonical = ctx.AppOrigin + "/blogs/tags/" + url.PathEscape(tag)
+	ctx.BodyClass = append(ctx.BodyClass, "body-blog-post-findAllByTag")
+
+	var count int64
+	var records []*BlogPostModel
+	err = BlogPostQueryAndCountReq(&BlogPostQueryOpts{
+		Records:       &records,
+		Count:         &count,
+		Limit:         ctx.GetLimit(),
+		Offset:        ctx.GetOffset(),
+		C:             c,
+		IsHTML:        true,
+		OnlyPublished: true,
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"error": err,
+			"tag":   tag,
+		}).Debug("BlogPostController.FindAllByTagPageHandler Error on find contents")
+	}
+
+	ctx.Pager.Count = count
+	var teaserList []string
+
+	preloadBlogPostsBlogSlugs(records)
+
+	for i := range records {
+		records[i].LoadTeaserData()
+
+		var teaserHTML bytes.Buffer
+
+		err = ctx.RenderTemplate(&teaserHTML, "blog-post/teaser", BlogPostTeaserTPL{
+			Ctx:    ctx,
+			Record: records[i],
+		})
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"err": err.Error(),
+			}).Error("BlogPostController.FindAllByTagPageHandler error on render teaser")
+		} else {
+			teaserList = append(teaserList, teaserHTML.String())
+		}
+	}
+
+	ctx.Set("hasRecords", len(records) > 0)
+	ctx.Set("records", teaserList)
+	ctx.Set("tag", tag)
+	ctx.Set("RequestPath", ctx.Request().URL.String())
+
+	return c.Render(http.StatusOK, "blog-post/findAll", &catu.TemplateCTX{
+		Ctx: ctx,
+	})
+}
+
+// FindAllByAuthorPageHandler - Published posts from all blogs with the author
+func (ctl *BlogPostController) FindAllByAuthorPageHandler(c echo.Context) error {
+	var err error
+	ctx := c.(*catu.RequestContext)
+
+	switch ctx.GetResponseContentType() {
+	case "application/json":
+		return ctl.Query(c)
+	}
+
+	userID := c.Param("userId")
+	if _, err = strconv.ParseUint(userID, 10, 64); err != nil {
+		return echo.NewHTTPError(http.StatusNotFound, "author not found")
+	}
+
+	var author user.UserModel
+	err = BlogAuthorFindOne(userID, &author)
+	if err != nil {
+		if isNotFoundError(err) {
+			return echo.NewHTTPError(http.StatusNotFound, "author not found")
+		}
+		return errors.Wrap(err, "error on find author")
+	}
+
+	authorItem := NewBlogPostAuthorItem(&author, 0)
+
+	ctx.Title = author.DisplayName
+	ctx.MetaTags.Title = author.DisplayName
+	ctx.MetaTags.Canonical = authorItem.LinkPermanent
+	ctx.BodyClass = append(ctx.BodyClass, "body-blog-post-findAllByAuthor")
+
+	var count int64
+	var records []*BlogPostModel
+	err = BlogPostQueryAndCountReq(&BlogPostQueryOpts{
+		Records:       &records,
+		Count:         &count,
+		Limit:         ctx.GetLimit(),
+		Offset:        ctx.GetOffset(),
+		C:             c,
+		IsHTML:        true,
+		OnlyPublished: true,
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"error":  err,
+			"userId": userID,
+		}).Debug("BlogPostController.FindAllByAuthorPageHandler Error on find contents")
+	}
+
+	ctx.Pager.Count = count
+	var teaserList []string
+
+	preloadBlogPostsBlogSlugs(records)
+
+	for i := range records {
+		records[i].LoadTeaserData()
+
+		var teaserHTML bytes.Buffer
+
+		err = ctx.RenderTemplate(&teaserHTML, "blog-post/teaser", BlogPostTeaserTPL{
+			Ctx:    ctx,
+			Record: records[i],
+		})
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"err": err.Error(),
+			}).Error("BlogPostController.FindAllByAuthorPageHandler error on render teaser")
+		} else {
+			teaserList = append(teaserList, teaserHTML.String())
+		}
+	}
+
+	ctx.Set("hasRecords", len(records) > 0)
+	ctx.Set("records", teaserList)
+	ctx.Set("author", authorItem)
+	ctx.Set("RequestPath", ctx.Request().URL.String())
 
 	return c.Render(http.StatusOK, "blog-post/findAll", &catu.TemplateCTX{
 		Ctx: ctx,
@@ -389,6 +833,11 @@ func (ctl *BlogPostController) FindOnePageHandler(c echo.Context) error {
 
 	err = loadCtxBlog(ctx)
 	if err != nil {
+		if isNotFoundError(err) {
+			if redirected, rErr := redirectFromOldURL(c); redirected || rErr != nil {
+				return rErr
+			}
+		}
 		return err
 	}
 
@@ -397,8 +846,20 @@ func (ctl *BlogPostController) FindOnePageHandler(c echo.Context) error {
 	}).Debug("BlogPostController.FindOnePageHandler id from params")
 
 	var record BlogPostModel
-	err = BlogPostFindOne(id, &record)
+	blog, hasBlog := ctx.Get("blog").(BlogModel)
+	if hasBlog {
+		// slugs are unique inside the blog
+		err = BlogPostFindOneInBlog(blog.ID, id, &record)
+		record.Blog = &blog
+	} else {
+		err = BlogPostFindOne(id, &record)
+	}
 	if err != nil {
+		if isNotFoundError(err) {
+			if redirected, rErr := redirectFromOldURL(c); redirected || rErr != nil {
+				return rErr
+			}
+		}
 		return err
 	}
 
@@ -410,7 +871,7 @@ func (ctl *BlogPostController) FindOnePageHandler(c echo.Context) error {
 	}
 
 	if !record.Published {
-		canAccessUnpublished := ctx.Can("access_contents_unpublished")
+		canAccessUnpublished := CanManageBlogPosts(ctx, "access_contents_unpublished", record.BlogID)
 		if !canAccessUnpublished {
 			return &catu.HTTPError{
 				Code:     403,
@@ -420,23 +881,45 @@ func (ctl *BlogPostController) FindOnePageHandler(c echo.Context) error {
 		}
 	}
 
+	redirected, err := setCanonicalURL(ctx, record.GetPath())
+	if redirected || err != nil {
+		return err
+	}
+
 	record.LoadData()
 
+	RegisterBlogPostView(ctx, &record)
+
 	ctx.Title = record.Title
 	ctx.BodyClass = append(ctx.BodyClass, "body-blog-post-findOne")
 
-	ctx.MetaTags.Title = record.Title
-	ctx.MetaTags.Description = record.Teaser
-	if record.HasFeaturedImage {
-		ctx.MetaTags.ImageURL = record.FeaturedImage[0].URLs["medium"]
+	err = LoadBlogPostPageMetadata(ctx, &record)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"err": err.Error(),
+		}).Error("BlogPostController.FindOnePageHandler error on load metadata")
 	}
 
-	// err = content.LoadMostReadBlockData(ctx)
-	// if err != nil {
-	// 	logrus.WithFields(logrus.Fields{
-	// 		"err": err.Error(),
-	// 	}).Error("BlogPostController.FindOnePageHandler error on render sidebar block")
-	// }
+	err = LoadBlogPostCommentsTemplateData(ctx, &record)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"err": err.Error(),
+		}).Error("BlogPostController.FindOnePageHandler error on load comments")
+	}
+
+	err = LoadRelatedBlogPostsBlockData(ctx, &record, relatedBlogPostsBlockLimit)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"err": err.Error(),
+		}).Error("BlogPostController.FindOnePageHandler error on render sidebar block")
+	}
+
+	err = LoadMostReadBlockData(ctx, record.BlogID)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"err": err.Error(),
+		}).Error("BlogPostController.FindOnePageHandler error on render most read block")
+	}
 
 	return ctx.Render(http.StatusOK, "blog-post/findOne", &catu.TemplateCTX{
 		Ctx:    ctx,
@@ -463,6 +946,11 @@ func loadCtxBlog(ctx *catu.RequestContext) error {
 			}
 		}
 
+		err = blog.LoadAlias()
+		if err != nil {
+			return errors.Wrap(err, "error on load blog alias")
+		}
+
 		err := blog.LoadTeaserData()
 		if err != nil {
 			return errors.Wrap(err, "error on load blog teaser")
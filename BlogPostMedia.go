@@ -0,0 +1,246 @@
+package blog
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/go-catupiry/catu"
+	"github.com/go-catupiry/files"
+	"gorm.io/gorm"
+)
+
+var galleryFieldCfg files.FieldConfigurationInterface
+var attachmentsFieldCfg files.FieldConfigurationInterface
+
+// max images or files loaded in one post field
+const blogPostMediaLimit = 200
+
+// BlogPostMediaModel Stores the order, caption and alt text of the post gallery images and attachments
+type BlogPostMediaModel struct {
+	ID         uint64 `gorm:"primaryKey;column:id" json:"id"`
+	BlogPostID uint64 `gorm:"uniqueIndex:blogPostMediaItem;column:blogPostId;not null" json:"blogPostId"`
+	Field      string `gorm:"uniqueIndex:blogPostMediaItem;column:field;type:varchar(50);not null" json:"field"`
+	MediaID    uint64 `gorm:"uniqueIndex:blogPostMediaItem;column:mediaId;not null" json:"mediaId"`
+	Order      int    `gorm:"column:order;not null;default:0" json:"order"`
+	Caption    string `gorm:"column:caption;type:varchar(500)" json:"caption"`
+	Alt        string `gorm:"column:alt;type:varchar(255)" json:"alt"`
+}
+
+// TableName get sql table name
+func (m *BlogPostMediaModel) TableName() string {
+	return "blog_post_media"
+}
+
+// BlogPostMediaItem - One gallery image or attachment file. ID is the image or file id
+type BlogPostMediaItem struct {
+	ID      uint64            `json:"id"`
+	Order   int               `json:"order"`
+	Caption string            `json:"caption"`
+	Alt     string            `json:"alt"`
+	Image   *files.ImageModel `json:"image,omitempty"`
+	File    *files.FileModel  `json:"file,omitempty"`
+}
+
+// LoadMedia - Load the gallery images and attachments sorted by the saved order
+func (r *BlogPostModel) LoadMedia() error {
+	if r.ID == 0 || galleryFieldCfg == nil || attachmentsFieldCfg == nil {
+		return nil
+	}
+
+	meta, err := findBlogPostMediaMeta(r.ID)
+	if err != nil {
+		return err
+	}
+
+	images, err := files.GetImagesInField(galleryFieldCfg.GetModelName(), galleryFieldCfg.GetFieldName(), r.GetIDString(), blogPostMediaLimit)
+	if err != nil {
+		return err
+	}
+
+	r.Gallery = []*BlogPostMediaItem{}
+	for _, image := range images {
+		item := BlogPostMediaItem{ID: image.ID, Image: image}
+		setBlogPostMediaItemMeta(&item, meta[galleryFieldCfg.GetFieldName()])
+		r.Gallery = append(r.Gallery, &item)
+	}
+	sortBlogPostMediaItems(r.Gallery)
+
+	attachments, err := files.GetFilesInField(attachmentsFieldCfg.GetModelName(), attachmentsFieldCfg.GetFieldName(), r.GetIDString(), blogPostMediaLimit)
+	if err != nil {
+		return err
+	}
+
+	r.Attachments = []*BlogPostMediaItem{}
+	for _, file := range attachments {
+		item := BlogPostMediaItem{ID: file.ID, File: file}
+		setBlogPostMediaItemMeta(&item, meta[attachmentsFieldCfg.GetFieldName()])
+		r.Attachments = append(r.Attachments, &item)
+	}
+	sortBlogPostMediaItems(r.Attachments)
+
+	return nil
+}
+
+// SaveMedia - Save the gallery and attachments, nil lists are not changed. The list position is the item order
+func (r *BlogPostModel) SaveMedia() error {
+	if galleryFieldCfg == nil || attachmentsFieldCfg == nil {
+		return nil
+	}
+
+	if r.Gallery != nil {
+		items := uniqueBlogPostMediaItems(r.Gallery)
+
+		err := files.UpdateFieldImagesById(r.GetIDString(), blogPostMediaIDs(items), galleryFieldCfg)
+		if err != nil {
+			return err
+		}
+
+		err = saveBlogPostMediaMeta(r.ID, galleryFieldCfg.GetFieldName(), items)
+		if err != nil {
+			return err
+		}
+	}
+
+	if r.Attachments != nil {
+		items := uniqueBlogPostMediaItems(r.Attachments)
+
+		err := files.UpdateFieldFilesById(r.GetIDString(), blogPostMediaIDs(items), attachmentsFieldCfg)
+		if err != nil {
+			return err
+		}
+
+		err = saveBlogPostMediaMeta(r.ID, attachmentsFieldCfg.GetFieldName(), items)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ClearMedia - Remove all gallery images and attachments from the post
+func (r *BlogPostModel) ClearMedia() error {
+	if galleryFieldCfg != nil {
+		err := files.UpdateFieldImagesById(r.GetIDString(), []string{}, galleryFieldCfg)
+		if err != nil {
+			return err
+		}
+	}
+
+	if attachmentsFieldCfg != nil {
+		err := files.UpdateFieldFilesById(r.GetIDString(), []string{}, attachmentsFieldCfg)
+		if err != nil {
+			return err
+		}
+	}
+
+	db := catu.GetDefaultDatabaseConnection()
+
+	return db.
+		Where("blogPostId = ?", r.ID).
+		Delete(&BlogPostMediaModel{}).Error
+}
+
+// findBlogPostMediaMeta - Post media meta by field and media id
+func findBlogPostMediaMeta(blogPostID uint64) (map[string]map[uint64]*BlogPostMediaModel, error) {
+	db := catu.GetDefaultDatabaseConnection()
+
+	records := []*BlogPostMediaModel{}
+	err := db.Where("blogPostId = ?", blogPostID).Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]map[uint64]*BlogPostMediaModel{}
+	for _, m := range records {
+		if result[m.Field] == nil {
+			result[m.Field] = map[uint64]*BlogPostMediaModel{}
+		}
+		result[m.Field][m.MediaID] = m
+	}
+
+	return result, nil
+}
+
+// setBlogPostMediaItemMeta - Items without meta are moved to the end of the list
+func setBlogPostMediaItemMeta(item *BlogPostMediaItem, meta map[uint64]*BlogPostMediaModel) {
+	m, ok := meta[item.ID]
+	if !ok {
+		item.Order = blogPostMediaLimit
+		return
+	}
+
+	item.Order = m.Order
+	item.Caption = m.Caption
+	item.Alt = m.Alt
+}
+
+func sortBlogPostMediaItems(items []*BlogPostMediaItem) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Order < items[j].Order
+	})
+
+	for i := range items {
+		items[i].Order = i
+	}
+}
+
+// saveBlogPostMediaMeta - Replace the field media meta records
+func saveBlogPostMediaMeta(blogPostID uint64, field string, items []*BlogPostMediaItem) error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.
+			Where("blogPostId = ? AND field = ?", blogPostID, field).
+			Delete(&BlogPostMediaModel{}).Error
+		if err != nil {
+			return err
+		}
+
+		if len(items) == 0 {
+			return nil
+		}
+
+		records := []*BlogPostMediaModel{}
+		for i, item := range items {
+			item.Order = i
+
+			records = append(records, &BlogPostMediaModel{
+				BlogPostID: blogPostID,
+				Field:      field,
+				MediaID:    item.ID,
+				Order:      i,
+				Caption:    item.Caption,
+				Alt:        item.Alt,
+			})
+		}
+
+		return tx.Create(&records).Error
+	})
+}
+
+// uniqueBlogPostMediaItems - Remove empty and duplicated items keeping the first
+func uniqueBlogPostMediaItems(items []*BlogPostMediaItem) []*BlogPostMediaItem {
+	result := []*BlogPostMediaItem{}
+	seen := map[uint64]bool{}
+
+	for _, item := range items {
+		if item == nil || item.ID == 0 || seen[item.ID] {
+			continue
+		}
+
+		seen[item.ID] = true
+		result = append(result, item)
+	}
+
+	return result
+}
+
+func blogPostMediaIDs(items []*BlogPostMediaItem) []string {
+	ids := []string{}
+	for _, item := range items {
+		ids = append(ids, strconv.FormatUint(item.ID, 10))
+	}
+
+	return ids
+}
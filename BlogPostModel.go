@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"html/template"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-catupiry/catu"
 	"github.com/go-catupiry/catu/helpers"
 	"github.com/go-catupiry/files"
 	"github.com/go-catupiry/tags"
+	"github.com/gookit/event"
 	"github.com/labstack/echo/v4"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -30,14 +32,19 @@ type BlogPostModel struct {
 	Title         string     `gorm:"column:title;type:varchar(255);not null" json:"title" filter:"param:title;type:string"`
 	Teaser        string     `gorm:"column:teaser;type:text" json:"teaser" filter:"param:teaser;type:string"`
 	Body          string     `gorm:"column:body;type:text" json:"body" filter:"param:body;type:string"`
+	BodyFormat    string     `gorm:"column:bodyFormat;type:varchar(20);not null;default:html" json:"bodyFormat"`
+	BodyHTML      string     `gorm:"column:bodyHTML;type:text" json:"bodyHTML"`
 	Published     bool       `gorm:"column:published;type:tinyint(1);default:0" json:"published"`
 	PublishedAt   *time.Time `gorm:"column:publishedAt;type:datetime" json:"publishedAt"`
+	UnpublishAt   *time.Time `gorm:"index:unpublishAt;column:unpublishAt;type:datetime" json:"unpublishAt"`
+	Status        string     `gorm:"index:status;column:status;type:varchar(20);not null;default:draft" json:"status" filter:"param:status;type:string"`
 	Highlighted   uint       `gorm:"column:highlighted;type:int(11);not null;default:0" json:"highlighted" filter:"param:highlighted;type:number"`
 	AllowComments bool       `gorm:"column:allowComments;type:tinyint(1);default:1" json:"allowComments"`
 	URLPath       string     `gorm:"column:urlPath;type:varchar(255);not null" json:"urlPath" filter:"param:urlPath;type:string"`
 	CreatedAt     time.Time  `gorm:"column:createdAt;type:datetime;not null" json:"createdAt"`
 	UpdatedAt     time.Time  `gorm:"column:updatedAt;type:datetime;not null" json:"updatedAt"`
 	CreatorID     *uint      `gorm:"index:creatorId;column:creatorId;type:int(11)" json:"creatorId,string"`
+	UpdatedByID   *uint64    `gorm:"index:updatedById;column:updatedById;type:int(11)" json:"updatedById,string"`
 	// Users         Users     `gorm:"joinForeignKey:creatorId;foreignKey:id" json:"usersList"` // We.js users table
 	BlogID *uint64    `gorm:"index:blogId;column:blogId;" json:"blogId" filter:"param:blogId;type:string"`
 	Blog   *BlogModel `gorm:"foreignKey:BlogID;references:ID;" json:"blog"`
@@ -47,12 +54,30 @@ type BlogPostModel struct {
 	HasFeaturedImage bool                `gorm:"-"`
 	FeaturedImage    []*files.ImageModel `gorm:"-" json:"featuredImage"`
 
+	// images and files with order, caption and alt text
+	Gallery     []*BlogPostMediaItem `gorm:"-" json:"gallery"`
+	Attachments []*BlogPostMediaItem `gorm:"-" json:"attachments"`
+
+	// byline authors, the creator if the post don't have authors
+	Authors []*BlogPostAuthorItem `gorm:"-" json:"authors"`
+
 	TagsRecords []tags.TermModel `gorm:"-" json:"-"`
 	Tags        []string         `gorm:"-" json:"tags"`
 
 	LinkPermanent string `gorm:"-" json:"linkPermanent"`
 
 	ShowInLists bool `gorm:"column:show_in_lists;" json:"showInLists" filter:"param:showInLists;type:bool"`
+
+	// tags text used in full text search
+	SearchTags string `gorm:"column:searchTags;type:text" json:"-"`
+	// highlighted text fragment, only set in search results
+	SearchSnippet string `gorm:"-" json:"searchSnippet,omitempty"`
+	// views count, only set in most read lists
+	Views int64 `gorm:"-" json:"views,omitempty"`
+
+	// blog slug cache used in GetPath
+	blogSlug   string
+	blogSlugID uint64
 }
 
 // TableName get sql table name
@@ -61,12 +86,42 @@ func (m *BlogPostModel) TableName() string {
 }
 
 func (r *BlogPostModel) LoadTeaserData() error {
+	r.RefreshTerms()
+	r.LoadFeaturedImage()
 	r.LoadPath()
+
+	err := r.LoadAuthors()
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"err": err,
+			"id":  r.ID,
+		}).Error("BlogPostModel.LoadTeaserData error on load authors")
+	}
+
 	return nil
 }
 
 func (r *BlogPostModel) LoadData() error {
+	r.RefreshTerms()
+	r.LoadFeaturedImage()
 	r.LoadPath()
+
+	err := r.LoadAuthors()
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"err": err,
+			"id":  r.ID,
+		}).Error("BlogPostModel.LoadData error on load authors")
+	}
+
+	err = r.LoadMedia()
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"err": err,
+			"id":  r.ID,
+		}).Error("BlogPostModel.LoadData error on load gallery and attachments")
+	}
+
 	return nil
 }
 
@@ -75,16 +130,6 @@ func (m *BlogPostModel) GetIDString() string {
 	return strconv.FormatInt(int64(m.ID), 10)
 }
 
-func (r *BlogPostModel) GetPath() string {
-	path := ""
-
-	if r.ID != 0 {
-		path += "/blog-post/" + strconv.FormatUint(r.ID, 10)
-	}
-
-	return path
-}
-
 func (r *BlogPostModel) LoadPath() error {
 	app := catu.GetApp()
 	r.LinkPermanent = app.GetConfiguration().Get("APP_ORIGIN") + r.GetPath()
@@ -96,7 +141,34 @@ func (m *BlogPostModel) Save() error {
 	var err error
 	db := catu.GetDefaultDatabaseConnection()
 
-	m.RefreshSlug()
+	oldTarget := ""
+	if m.ID != 0 {
+		var old BlogPostModel
+		err = db.Select("id", "blogId").Where("id = ?", m.ID).Find(&old).Error
+		if err != nil {
+			return err
+		}
+		oldTarget = old.GetTargetPath()
+	}
+
+	err = m.RefreshSlug()
+	if err != nil {
+		return err
+	}
+
+	if m.BodyFormat != "" && !IsValidBlogPostBodyFormat(m.BodyFormat) {
+		return errors.New("invalid body format: " + m.BodyFormat)
+	}
+
+	err = m.RenderBody()
+	if err != nil {
+		return errors.Wrap(err, "error on render body")
+	}
+
+	if m.Tags != nil {
+		m.Tags = normalizeTags(m.Tags)
+		m.SearchTags = strings.Join(m.Tags, " ")
+	}
 
 	if m.ID == 0 {
 		// create ....
@@ -112,30 +184,96 @@ func (m *BlogPostModel) Save() error {
 		}
 	}
 
-	err = files.UpdateFieldImagesByObjects(m.GetIDString(), m.FeaturedImage, featuredImageFieldCfg)
+	// nil featured image is not changed, use a empty list to remove it
+	if m.FeaturedImage != nil {
+		err = files.UpdateFieldImagesByObjects(m.GetIDString(), m.FeaturedImage, featuredImageFieldCfg)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"err": err,
+				"id":  m.ID,
+			}).Error("BlogPostModel.Save error on update featuredImage")
+		}
+	}
+
+	err = m.SaveMedia()
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"err": err,
+			"id":  m.ID,
+		}).Error("BlogPostModel.Save error on update gallery and attachments")
+	}
+
+	err = m.SaveAuthors()
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"err": err,
+			"id":  m.ID,
+		}).Error("BlogPostModel.Save error on update authors")
+	}
+
+	err = saveTags(blogPostTagsFieldCfg, m.GetIDString(), m.Tags)
 	if err != nil {
 		logrus.WithFields(logrus.Fields{
 			"err": err,
 			"id":  m.ID,
-		}).Error("BlogPostModel.Save error on update featuredImage")
+		}).Error("BlogPostModel.Save error on update tags")
 	}
 
+	err = m.CreateRevision()
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"err": err,
+			"id":  m.ID,
+		}).Error("BlogPostModel.Save error on create revision")
+	}
+
+	if oldTarget != "" && oldTarget != m.GetTargetPath() {
+		// the post moved to other blog
+		err = moveBlogURLAlias(oldTarget, m.GetTargetPath())
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"err": err,
+				"id":  m.ID,
+			}).Error("BlogPostModel.Save error on move url alias")
+		}
+	}
+
+	err = m.UrlAliasUpsert(nil)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"err": err,
+			"id":  m.ID,
+		}).Error("BlogPostModel.Save error on upsert url alias")
+	}
+
+	indexBlogPost(m)
+
 	return nil
 }
 
+// CreateRevision - Snapshot the post content if it changed since the last revision
+func (m *BlogPostModel) CreateRevision() error {
+	var latest BlogPostRevisionModel
+	err := BlogPostRevisionFindLatest(m.GetIDString(), &latest)
+	if err != nil {
+		return errors.Wrap(err, "error on find latest revision")
+	}
+
+	if latest.ID != 0 && latest.HasSameContent(m) {
+		return nil
+	}
+
+	return NewBlogPostRevision(m, m.UpdatedByID).Save()
+}
+
 func (m *BlogPostModel) Publish() error {
 	if m.Published {
 		// already published, skip
 		return nil
 	}
 
-	db := catu.GetDefaultDatabaseConnection()
-
-	m.Published = true
-	now := time.Now()
-	m.PublishedAt = &now
-
-	err := db.Model(&m).Updates(map[string]interface{}{"published": m.Published, "publishedAt": m.PublishedAt}).Error
+	// keep the scheduled publication date, ChangeStatus sets it to now if it is empty
+	err := m.ChangeStatus(BlogPostStatusPublished)
 	if err != nil {
 		return errors.Wrap(err, "error on publish blog posts")
 	}
@@ -149,12 +287,7 @@ func (m *BlogPostModel) UnPublish() error {
 		return nil
 	}
 
-	db := catu.GetDefaultDatabaseConnection()
-
-	m.Published = false
-	m.PublishedAt = nil
-
-	err := db.Model(&m).Updates(map[string]interface{}{"published": m.Published, "publishedAt": m.PublishedAt}).Error
+	err := m.ChangeStatus(BlogPostStatusArchived)
 	if err != nil {
 		return errors.Wrap(err, "error on unPublish blog posts")
 	}
@@ -162,50 +295,343 @@ func (m *BlogPostModel) UnPublish() error {
 	return nil
 }
 
-func FindUnPublishedBlogPosts(records *[]*BlogPostModel, limit int) error {
+// FindUnPublishedBlogPosts - Find scheduled blog posts with publishedAt before the cutoff time.
+// Records are ordered by id and only ids after afterID are returned, use it to page the backlog
+func FindUnPublishedBlogPosts(records *[]*BlogPostModel, cutoff time.Time, afterID uint64, limit int) error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	return db.
+		Order("id ASC").
+		Where("status = ?", BlogPostStatusScheduled).
+		Where("publishedAt <= ?", cutoff).
+		Where("id > ?", afterID).
+		Limit(limit).
+		Find(records).Error
+}
+
+// FindExpiredBlogPosts - Find published blog posts with unpublishAt before the given time, ordered by id
+func FindExpiredBlogPosts(records *[]*BlogPostModel, now time.Time, afterID uint64, limit int) error {
 	db := catu.GetDefaultDatabaseConnection()
 
 	return db.
-		Order("createdAt ASC").
-		Where("published != ?", "1").
-		Where("publishedAt <= DATE_ADD(now(), INTERVAL 1 MINUTE)").
+		Order("id ASC").
+		Where("status = ?", BlogPostStatusPublished).
+		Where("unpublishAt IS NOT NULL AND unpublishAt <= ?", now).
+		Where("id > ?", afterID).
 		Limit(limit).
 		Find(records).Error
 }
 
-func (r *BlogPostModel) RefreshSlug() {
+// RefreshSlug - Generate the post slug from title if it is empty or if the title changed.
+// The slug is unique inside the blog
+func (r *BlogPostModel) RefreshSlug() error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	source := r.URLPath
+
+	if r.ID != 0 {
+		var old BlogPostModel
+		err := db.Select("id", "title", "urlPath").Where("id = ?", r.ID).Find(&old).Error
+		if err != nil {
+			return err
+		}
+
+		if old.ID != 0 && r.URLPath == old.URLPath && r.Title != old.Title {
+			source = r.Title
+		}
+	}
 
+	if source == "" {
+		source = r.Title
+	}
+
+	s, err := uniqueSlug(makeSlug(source, "post"), func(s string) (bool, error) {
+		return blogPostSlugExists(s, r.BlogID, r.ID)
+	})
+	if err != nil {
+		return err
+	}
+
+	r.URLPath = s
+
+	return nil
+}
+
+// UrlAliasUpsert - Set the /blogs/<blog-slug>/<post-slug> alias for the post. The blog is loaded if it is nil
+func (r *BlogPostModel) UrlAliasUpsert(blog *BlogModel) error {
+	if r.BlogID == nil || r.URLPath == "" {
+		return nil
+	}
+
+	if blog == nil {
+		blog = &BlogModel{}
+		err := BlogFindOne(strconv.FormatUint(*r.BlogID, 10), blog)
+		if err != nil {
+			return err
+		}
+	}
+
+	alias := "/blogs/" + blog.URLUniquePath + "/" + r.URLPath
+
+	_, err := upsertBlogURLAlias(alias, r.GetTargetPath())
+
+	return err
+}
+
+// GetTargetPath - Route path with blog and post ids, used as the url alias target
+func (r *BlogPostModel) GetTargetPath() string {
+	if r.BlogID == nil {
+		return ""
+	}
+
+	return "/blogs/" + strconv.FormatUint(*r.BlogID, 10) + "/" + r.GetIDString()
 }
 
 func (r *BlogPostModel) Delete() error {
 	db := catu.GetDefaultDatabaseConnection()
-	return db.Unscoped().Delete(&r).Error
+
+	err := db.Unscoped().Delete(&r).Error
+	if err != nil {
+		return err
+	}
+
+	if target := r.GetTargetPath(); target != "" {
+		err = deleteBlogURLAlias(target)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"err": err,
+				"id":  r.ID,
+			}).Error("BlogPostModel.Delete error on delete url alias")
+		}
+	}
+
+	removeBlogPostFromIndex(r.ID)
+
+	err = clearTags(blogPostTagsFieldCfg, r.GetIDString())
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"err": err,
+			"id":  r.ID,
+		}).Error("BlogPostModel.Delete error on clear tags")
+	}
+
+	err = clearImages(featuredImageFieldCfg, r.GetIDString())
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"err": err,
+			"id":  r.ID,
+		}).Error("BlogPostModel.Delete error on clear featuredImage")
+	}
+
+	err = r.ClearMedia()
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"err": err,
+			"id":  r.ID,
+		}).Error("BlogPostModel.Delete error on clear gallery and attachments")
+	}
+
+	err = r.ClearAuthors()
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"err": err,
+			"id":  r.ID,
+		}).Error("BlogPostModel.Delete error on clear authors")
+	}
+
+	err = BlogPostStatsDeleteAll(r.GetIDString())
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"err": err,
+			"id":  r.ID,
+		}).Error("BlogPostModel.Delete error on delete stats")
+	}
+
+	err = BlogCommentDeleteAll(r.GetIDString())
+	if err != nil {
+		return err
+	}
+
+	return BlogPostRevisionDeleteAll(r.GetIDString())
+}
+
+// ScheduledPublishResult - Result of one scheduled blog posts publisher run
+type ScheduledPublishResult struct {
+	DryRun    bool     `json:"dryRun"`
+	Published []uint64 `json:"published"`
+	Failed    []uint64 `json:"failed"`
 }
 
+// PublishSchenduledBlogPosts - Publish all scheduled blog posts with publishedAt in the past, used in the cron-job event.
+// Uses the BLOG_PUBLISH_BATCH_SIZE (default 25) and BLOG_PUBLISH_DRY_RUN ("true" to only log) configurations
 func PublishSchenduledBlogPosts(app catu.App) error {
-	posts := []*BlogPostModel{}
-	err := FindUnPublishedBlogPosts(&posts, 25)
-	if err != nil {
+	cfg := app.GetConfiguration()
+
+	batchSize, _ := strconv.Atoi(cfg.GetF("BLOG_PUBLISH_BATCH_SIZE", "25"))
+	dryRun := cfg.GetF("BLOG_PUBLISH_DRY_RUN", "") == "true"
+
+	_, err := RunScheduledBlogPostsPublisher(time.Now().Add(time.Minute), batchSize, dryRun)
+	return err
+}
+
+// RunScheduledBlogPostsPublisher - Drain the scheduled blog posts backlog with publishedAt <= cutoff in batches.
+// With dryRun the records are only reported
+func RunScheduledBlogPostsPublisher(cutoff time.Time, batchSize int, dryRun bool) (*ScheduledPublishResult, error) {
+	if batchSize <= 0 {
+		batchSize = 25
+	}
+
+	result := ScheduledPublishResult{
+		DryRun:    dryRun,
+		Published: []uint64{},
+		Failed:    []uint64{},
+	}
+
+	var lastID uint64
+
+	for {
+		posts := []*BlogPostModel{}
+		err := FindUnPublishedBlogPosts(&posts, cutoff, lastID, batchSize)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"error": fmt.Sprintf("%+v\n", err),
+			}).Error("PublishSchenduledBlogPosts error on find blog posts to publish")
+			return &result, errors.Wrap(err, "error on find scheduled blog posts")
+		}
+
+		if len(posts) == 0 {
+			break
+		}
+
 		logrus.WithFields(logrus.Fields{
-			"error": fmt.Sprintf("%+v\n", err),
-		}).Error("PublishSchenduledBlogPosts error on publish blogs")
+			"count":  len(posts),
+			"dryRun": dryRun,
+		}).Debug("PublishSchenduledBlogPosts count to publish")
+
+		for _, c := range posts {
+			lastID = c.ID
+
+			if dryRun {
+				logrus.WithFields(logrus.Fields{
+					"id":          c.ID,
+					"title":       c.Title,
+					"publishedAt": c.PublishedAt,
+				}).Info("PublishSchenduledBlogPosts dry run, blog post would be published")
+
+				result.Published = append(result.Published, c.ID)
+				continue
+			}
+
+			before := c.AuditFields()
+
+			err := c.Publish()
+			if err != nil {
+				logrus.WithFields(logrus.Fields{
+					"id":    c.ID,
+					"error": fmt.Sprintf("%+v\n", err),
+				}).Error("PublishSchenduledBlogPosts error on publish blog posts record")
+
+				result.Failed = append(result.Failed, c.ID)
+				continue
+			}
+
+			logrus.WithFields(logrus.Fields{
+				"id": c.ID,
+			}).Info("PublishSchenduledBlogPosts blog posts published")
+
+			auditBlogPost(NewBlogAuditSystemActor(), BlogAuditActionPublish, c, before, c.AuditFields())
+
+			result.Published = append(result.Published, c.ID)
+		}
+
+		if len(posts) < batchSize {
+			break
+		}
 	}
 
 	logrus.WithFields(logrus.Fields{
-		"count": len(posts),
-	}).Debug("PublishSchenduledBlogPosts count to publish")
+		"published": len(result.Published),
+		"failed":    len(result.Failed),
+		"dryRun":    dryRun,
+	}).Debug("PublishSchenduledBlogPosts done")
+
+	return &result, nil
+}
+
+// UnPublishExpiredBlogPosts - UnPublish all published blog posts with unpublishAt in the past, used in the cron-job event.
+// Uses the same batch size and dry run configurations of PublishSchenduledBlogPosts
+func UnPublishExpiredBlogPosts(app catu.App) error {
+	cfg := app.GetConfiguration()
+
+	batchSize, _ := strconv.Atoi(cfg.GetF("BLOG_PUBLISH_BATCH_SIZE", "25"))
+	if batchSize <= 0 {
+		batchSize = 25
+	}
+	dryRun := cfg.GetF("BLOG_PUBLISH_DRY_RUN", "") == "true"
+
+	now := time.Now()
+	var lastID uint64
 
-	for _, c := range posts {
-		err := c.Publish()
+	for {
+		posts := []*BlogPostModel{}
+		err := FindExpiredBlogPosts(&posts, now, lastID, batchSize)
 		if err != nil {
 			logrus.WithFields(logrus.Fields{
 				"error": fmt.Sprintf("%+v\n", err),
-			}).Error("PublishSchenduledBlogPosts error on publish blog posts record")
+			}).Error("UnPublishExpiredBlogPosts error on find expired blog posts")
+			return errors.Wrap(err, "error on find expired blog posts")
 		}
 
-		logrus.WithFields(logrus.Fields{
-			"id": c.ID,
-		}).Info("PublishSchenduledBlogPosts blog posts published")
+		if len(posts) == 0 {
+			break
+		}
+
+		for _, c := range posts {
+			lastID = c.ID
+
+			if dryRun {
+				logrus.WithFields(logrus.Fields{
+					"id":          c.ID,
+					"title":       c.Title,
+					"unpublishAt": c.UnpublishAt,
+				}).Info("UnPublishExpiredBlogPosts dry run, blog post would be unpublished")
+				continue
+			}
+
+			unpublishAt := c.UnpublishAt
+			before := c.AuditFields()
+
+			err := c.UnPublish()
+			if err != nil {
+				logrus.WithFields(logrus.Fields{
+					"id":    c.ID,
+					"error": fmt.Sprintf("%+v\n", err),
+				}).Error("UnPublishExpiredBlogPosts error on unpublish blog post record")
+				continue
+			}
+
+			logrus.WithFields(logrus.Fields{
+				"id":          c.ID,
+				"unpublishAt": unpublishAt,
+			}).Info("UnPublishExpiredBlogPosts blog post expired")
+
+			auditBlogPost(NewBlogAuditSystemActor(), BlogAuditActionUnpublish, c, before, c.AuditFields())
+
+			err, _ = app.GetEvents().Fire("blog-post-expired", event.M{
+				"app":    app,
+				"record": c,
+			})
+			if err != nil {
+				logrus.WithFields(logrus.Fields{
+					"id":    c.ID,
+					"error": fmt.Sprintf("%+v\n", err),
+				}).Error("UnPublishExpiredBlogPosts error on fire blog-post-expired event")
+			}
+		}
+
+		if len(posts) < batchSize {
+			break
+		}
 	}
 
 	return nil
@@ -231,6 +657,25 @@ func BlogPostFindOne(id string, record *BlogPostModel) error {
 		First(&record).Error
 }
 
+// BlogPostFindOneInBlog - Find one blog post by id or slug inside one blog
+func BlogPostFindOneInBlog(blogID uint64, id string, record *BlogPostModel) error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	return db.
+		Where("blogId = ?", blogID).
+		Where("id = ? OR urlPath = ?", id, id).
+		First(&record).Error
+}
+
+// clearImages - Remove all images from the record image field
+func clearImages(cfg files.FieldConfigurationInterface, modelID string) error {
+	if cfg == nil {
+		return nil
+	}
+
+	return files.UpdateFieldImagesById(modelID, []string{}, cfg)
+}
+
 func (r *BlogPostModel) LoadFeaturedImage() error {
 	var err error
 
@@ -238,7 +683,7 @@ func (r *BlogPostModel) LoadFeaturedImage() error {
 		"id": r.ID,
 	}).Debug("BlogPostModel.LoadFeaturedImage will refresh")
 
-	featuredImage, err := files.GetImagesInField("BlogPostModel", "featuredImage", strconv.FormatUint(r.ID, 10), 1)
+	featuredImage, err := files.GetImagesInField(featuredImageFieldCfg.GetModelName(), featuredImageFieldCfg.GetFieldName(), strconv.FormatUint(r.ID, 10), 1)
 	if err != nil {
 		logrus.WithFields(logrus.Fields{
 			"id":         r.ID,
@@ -253,10 +698,8 @@ func (r *BlogPostModel) LoadFeaturedImage() error {
 		"images_len": len(featuredImage),
 	}).Debug("BlogPostModel.LoadFeaturedImage featuredImage found")
 
-	if len(featuredImage) > 0 {
-		r.FeaturedImage = featuredImage
-
-	}
+	r.FeaturedImage = featuredImage
+	r.HasFeaturedImage = len(featuredImage) > 0
 
 	return nil
 }
@@ -281,21 +724,78 @@ type BlogPostQueryOpts struct {
 	Offset  int
 	C       echo.Context
 	IsHTML  bool
+	// Skip unpublished posts even if the user can access them, used in public feeds
+	OnlyPublished bool
 }
 
 func BlogPostQueryAndCountReq(opts *BlogPostQueryOpts) error {
+	c := opts.C
+
+	q := c.QueryParam("q")
+
+	query := buildBlogPostQueryFromReq(opts)
+	if query == nil {
+		return nil
+	}
+
+	orderColumn, orderIsDesc, orderValid := helpers.ParseUrlQueryOrder(c.QueryParam("order"))
+
+	if q != "" {
+		query = setBlogPostSearchQuery(query, q, !orderValid)
+	}
+
+	if orderValid {
+		query = query.Order(clause.OrderByColumn{
+			Column: clause.Column{Table: clause.CurrentTable, Name: orderColumn},
+			Desc:   orderIsDesc,
+		})
+	} else if q == "" || GetBlogPostSearchBackend().Relevance(q) == nil {
+		query = query.Order("highlighted DESC").
+			Order("publishedAt DESC").
+			Order("id DESC")
+	}
+
+	query = query.Limit(opts.Limit).
+		Offset(opts.Offset)
+
+	err := query.Find(opts.Records).Error
+	if err != nil {
+		return err
+	}
+
+	if q != "" {
+		for _, r := range *opts.Records {
+			r.LoadSearchSnippet(q)
+		}
+	}
+
+	return BlogPostCountReq(opts)
+}
+
+// buildBlogPostQueryFromReq - Set the filters from request in the blog post query, returns nil if the filters are not allowed
+func buildBlogPostQueryFromReq(opts *BlogPostQueryOpts) *gorm.DB {
 	db := catu.GetDefaultDatabaseConnection()
 
 	c := opts.C
 	ctx := c.(*catu.RequestContext)
 
-	q := c.QueryParam("q")
 	showInLists := c.QueryParam("showInLists")
 	blogId := c.QueryParam("blogId")
 
 	query := db
 
 	canAccessUnpublished := ctx.Can("access_blogs_unpublished")
+	if !canAccessUnpublished {
+		// blog editors can access unpublished posts from their blogs
+		filterBlogID := parseBlogID(blogId)
+		if opts.BlogID != 0 {
+			id := uint64(opts.BlogID)
+			filterBlogID = &id
+		}
+
+		canAccessUnpublished = CanManageBlogPosts(ctx, "access_blogs_unpublished", filterBlogID)
+	}
+
 	if !canAccessUnpublished {
 		p := c.QueryParam("published")
 		if p != "" {
@@ -314,12 +814,6 @@ func BlogPostQueryAndCountReq(opts *BlogPostQueryOpts) error {
 	}
 	query = queryI.(*gorm.DB)
 
-	if q != "" {
-		query = query.Where(
-			db.Where("title LIKE ?", "%"+q+"%").Or(db.Where("body LIKE ?", "%"+q+"%")),
-		)
-	}
-
 	if showInLists == "" {
 		// default:
 		if opts.IsHTML {
@@ -333,69 +827,62 @@ func BlogPostQueryAndCountReq(opts *BlogPostQueryOpts) error {
 		query = query.Where("show_in_lists = ?", "1")
 	}
 
-	if !canAccessUnpublished {
+	if !canAccessUnpublished || opts.OnlyPublished {
 		query = query.Where("published = ?", "1")
 	}
 
-	if blogId != "" {
+	if opts.BlogID != 0 {
+		query = query.Where("blogId = ?", opts.BlogID)
+	} else if blogId != "" {
 		query = query.Where("blogId = ?", blogId)
 	}
 
-	orderColumn, orderIsDesc, orderValid := helpers.ParseUrlQueryOrder(c.QueryParam("order"))
+	tag := c.Param("tag")
+	if tag == "" {
+		tag = c.QueryParam("tag")
+	}
 
-	if orderValid {
-		query = query.Order(clause.OrderByColumn{
-			Column: clause.Column{Table: clause.CurrentTable, Name: orderColumn},
-			Desc:   orderIsDesc,
-		})
-	} else {
-		query = query.Order("highlighted DESC").
-			Order("publishedAt DESC").
-			Order("id DESC")
+	if tag != "" {
+		query = query.Where("id IN (?)", blogPostTagSubQuery(db, tag))
 	}
 
-	query = query.Limit(opts.Limit).
-		Offset(opts.Offset)
+	author := c.Param("userId")
+	if author == "" {
+		author = c.QueryParam("author")
+	}
 
-	err = query.Find(opts.Records).Error
-	if err != nil {
-		return err
+	if author != "" {
+		query = query.Where("id IN (?)", blogPostAuthorSubQuery(db, author))
 	}
 
-	return BlogPostCountReq(opts)
+	return query
 }
 
 func BlogPostCountReq(opts *BlogPostQueryOpts) error {
-	db := catu.GetDefaultDatabaseConnection()
-
 	c := opts.C
 
 	q := c.QueryParam("q")
-	// showInLists := c.QueryParam("showInLists")
-
-	ctx := c.(*catu.RequestContext)
 
 	// Count ...
-	queryCount := db
+	queryCount := buildBlogPostQueryFromReq(opts)
+	if queryCount == nil {
+		return nil
+	}
 
 	if q != "" {
-		queryCount = queryCount.Or(
-			db.Where("title LIKE ?", "%"+q+"%"),
-			db.Where("body LIKE ?", "%"+q+"%"),
-		)
+		queryCount = setBlogPostSearchQuery(queryCount, q, false)
 	}
 
-	queryICount, err := ctx.Query.SetDatabaseQueryForModel(queryCount, &BlogModel{})
+	err := queryCount.
+		Model(&BlogPostModel{}).
+		Count(opts.Count).Error
 	if err != nil {
 		logrus.WithFields(logrus.Fields{
 			"error": fmt.Sprintf("%+v\n", err),
 		}).Error("BlogQueryAndCountReq count error")
 	}
-	queryCount = queryICount.(*gorm.DB)
 
-	return queryCount.
-		Table("blog_posts").
-		Count(opts.Count).Error
+	return err
 }
 
 func BlogPostCountQuery(count *int64) error {
@@ -411,7 +898,7 @@ func BlogPostCountQuery(count *int64) error {
 
 func NewBlogPostModel() *BlogPostModel {
 	return &BlogPostModel{
-
+		Status:      BlogPostStatusDraft,
 		ShowInLists: true,
 	}
 }
@@ -427,3 +914,22 @@ func BlogPostFindLatest(records *[]*BlogPostModel, limit int) error {
 		Limit(limit).
 		Find(records).Error
 }
+
+// BlogPostFindInRSS - Find latest published blog posts marked to show in RSS, filter by blog if blogID is set
+func BlogPostFindInRSS(blogID string, records *[]*BlogPostModel, limit int) error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	query := db.
+		Where("published = ?", true).
+		Where("inRSS = ?", true)
+
+	if blogID != "" {
+		query = query.Where("blogId = ?", blogID)
+	}
+
+	return query.
+		Order("publishedAt DESC").
+		Order("id DESC").
+		Limit(limit).
+		Find(records).Error
+}
@@ -0,0 +1,166 @@
+package blog
+
+import (
+	"bytes"
+	"sort"
+	"time"
+
+	"github.com/go-catupiry/catu"
+	"github.com/sirupsen/logrus"
+)
+
+// related posts score weights
+const (
+	relatedSharedTagWeight = 2.0
+	relatedSameBlogWeight  = 1.0
+	// post age in days where the score is divided by two
+	relatedRecencyDays = 30.0
+	// max candidates loaded from database
+	relatedCandidatesLimit = 100
+	// posts in the blog post page block
+	relatedBlogPostsBlockLimit = 5
+)
+
+type blogPostSharedTags struct {
+	ID     uint64
+	Shared int64
+}
+
+// FindRelatedBlogPosts - Find published posts related to the post by shared tags and same blog, weighted by recency
+func FindRelatedBlogPosts(post *BlogPostModel, limit int, now time.Time, records *[]*BlogPostModel) error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	shared := map[uint64]int64{}
+	candidateIDs := []uint64{}
+
+	if len(post.Tags) > 0 {
+		rows := []blogPostSharedTags{}
+		err := db.
+			Table("modelsterms").
+			Select("modelsterms.modelId AS id, COUNT(DISTINCT terms.id) AS shared").
+			Joins("INNER JOIN terms ON terms.id = modelsterms.termId").
+			Where("modelsterms.modelName = ? AND modelsterms.field = ? AND modelsterms.vocabularyName = ?", "blog-post", "tags", blogTagsVocabulary).
+			Where("terms.text IN ?", post.Tags).
+			Where("modelsterms.modelId <> ?", post.ID).
+			Group("modelsterms.modelId").
+			Order("shared DESC").
+			Limit(relatedCandidatesLimit).
+			Scan(&rows).Error
+		if err != nil {
+			return err
+		}
+
+		for _, r := range rows {
+			shared[r.ID] = r.Shared
+			candidateIDs = append(candidateIDs, r.ID)
+		}
+	}
+
+	if post.BlogID != nil {
+		sameBlogIDs := []uint64{}
+		err := db.Model(&BlogPostModel{}).
+			Where("blogId = ? AND id <> ? AND published = ?", *post.BlogID, post.ID, true).
+			Order("publishedAt DESC").
+			Limit(limit*3).
+			Pluck("id", &sameBlogIDs).Error
+		if err != nil {
+			return err
+		}
+
+		candidateIDs = append(candidateIDs, sameBlogIDs...)
+	}
+
+	if len(candidateIDs) == 0 {
+		*records = []*BlogPostModel{}
+		return nil
+	}
+
+	candidates := []*BlogPostModel{}
+	err := db.
+		Where("id IN ? AND published = ?", candidateIDs, true).
+		Find(&candidates).Error
+	if err != nil {
+		return err
+	}
+
+	scores := map[uint64]float64{}
+	for _, c := range candidates {
+		scores[c.ID] = relatedBlogPostScore(post, c, shared[c.ID], now)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if scores[candidates[i].ID] == scores[candidates[j].ID] {
+			return candidates[i].ID > candidates[j].ID
+		}
+		return scores[candidates[i].ID] > scores[candidates[j].ID]
+	})
+
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	*records = candidates
+
+	return nil
+}
+
+// relatedBlogPostScore - Shared tags and same blog score with recency weight 1 / (1 + ageDays/relatedRecencyDays)
+func relatedBlogPostScore(post, candidate *BlogPostModel, sharedTags int64, now time.Time) float64 {
+	score := float64(sharedTags) * relatedSharedTagWeight
+
+	if post.BlogID != nil && sameBlogID(post.BlogID, candidate.BlogID) {
+		score += relatedSameBlogWeight
+	}
+
+	date := candidate.CreatedAt
+	if candidate.PublishedAt != nil {
+		date = *candidate.PublishedAt
+	}
+
+	ageDays := now.Sub(date).Hours() / 24
+	if ageDays < 0 {
+		ageDays = 0
+	}
+
+	return score / (1 + ageDays/relatedRecencyDays)
+}
+
+// LoadRelatedBlogPostsBlockData - Render the related posts teasers in the "relatedPosts" context variable
+func LoadRelatedBlogPostsBlockData(ctx *catu.RequestContext, post *BlogPostModel, limit int) error {
+	records := []*BlogPostModel{}
+	err := FindRelatedBlogPosts(post, limit, time.Now(), &records)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"id":  post.ID,
+			"err": err,
+		}).Error("LoadRelatedBlogPostsBlockData error on find related posts")
+		return err
+	}
+
+	var teaserList []string
+
+	preloadBlogPostsBlogSlugs(records)
+
+	for i := range records {
+		records[i].LoadTeaserData()
+
+		var teaserHTML bytes.Buffer
+
+		err = ctx.RenderTemplate(&teaserHTML, "blog-post/teaser", BlogPostTeaserTPL{
+			Ctx:    ctx,
+			Record: records[i],
+		})
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"err": err.Error(),
+			}).Error("LoadRelatedBlogPostsBlockData error on render teaser")
+		} else {
+			teaserList = append(teaserList, teaserHTML.String())
+		}
+	}
+
+	ctx.Set("hasRelatedPosts", len(teaserList) > 0)
+	ctx.Set("relatedPosts", teaserList)
+
+	return nil
+}
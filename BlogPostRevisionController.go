@@ -0,0 +1,202 @@
+package blog
+
+import (
+	"net/http"
+
+	"github.com/go-catupiry/catu"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type BlogPostRevisionJSONResponse struct {
+	catu.BaseListReponse
+	Records []*BlogPostRevisionModel `json:"blog-post-revision"`
+}
+
+type BlogPostRevisionFindOneJSONResponse struct {
+	Record *BlogPostRevisionModel `json:"blog-post-revision"`
+}
+
+type BlogPostRevisionDiffJSONResponse struct {
+	From *BlogPostRevisionModel `json:"from"`
+	To   *BlogPostRevisionModel `json:"to"`
+	Diff string                 `json:"diff"`
+}
+
+// Http blog post revision controller | struct with http handlers for blog post revisions
+type BlogPostRevisionController struct {
+	App catu.App
+}
+
+func (ctl *BlogPostRevisionController) Query(c echo.Context) error {
+	post, err := findRevisionsBlogPost(c)
+	if err != nil {
+		return err
+	}
+
+	records := []*BlogPostRevisionModel{}
+	err = BlogPostRevisionFindMany(post.GetIDString(), &records)
+	if err != nil {
+		return errors.Wrap(err, "BlogPostRevisionController.Query error on find revisions")
+	}
+
+	resp := BlogPostRevisionJSONResponse{
+		Records: records,
+	}
+
+	resp.Meta.Count = int64(len(records))
+
+	return c.JSON(http.StatusOK, &resp)
+}
+
+func (ctl *BlogPostRevisionController) FindOne(c echo.Context) error {
+	post, err := findRevisionsBlogPost(c)
+	if err != nil {
+		return err
+	}
+
+	record, err := findBlogPostRevision(post, c.Param("revisionId"))
+	if err != nil {
+		return err
+	}
+
+	resp := BlogPostRevisionFindOneJSONResponse{
+		Record: record,
+	}
+
+	return c.JSON(http.StatusOK, &resp)
+}
+
+// Diff - Unified diff between two revisions, set with ?from= and ?to= query params.
+// If "to" is empty the latest revision will be used
+func (ctl *BlogPostRevisionController) Diff(c echo.Context) error {
+	post, err := findRevisionsBlogPost(c)
+	if err != nil {
+		return err
+	}
+
+	fromID := c.QueryParam("from")
+	if fromID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "from query param is required")
+	}
+
+	from, err := findBlogPostRevision(post, fromID)
+	if err != nil {
+		return err
+	}
+
+	var to *BlogPostRevisionModel
+	toID := c.QueryParam("to")
+	if toID == "" {
+		var latest BlogPostRevisionModel
+		err = BlogPostRevisionFindLatest(post.GetIDString(), &latest)
+		if err != nil {
+			return errors.Wrap(err, "BlogPostRevisionController.Diff error on find latest revision")
+		}
+		to = &latest
+	} else {
+		to, err = findBlogPostRevision(post, toID)
+		if err != nil {
+			return err
+		}
+	}
+
+	resp := BlogPostRevisionDiffJSONResponse{
+		From: from,
+		To:   to,
+		Diff: from.Diff(to),
+	}
+
+	return c.JSON(http.StatusOK, &resp)
+}
+
+// Restore - Restore the revision content in the blog post, saving it as a new revision
+func (ctl *BlogPostRevisionController) Restore(c echo.Context) error {
+	ctx := c.(*catu.RequestContext)
+
+	post, err := findRevisionsBlogPost(c)
+	if err != nil {
+		return err
+	}
+
+	revision, err := findBlogPostRevision(post, c.Param("revisionId"))
+	if err != nil {
+		return err
+	}
+
+	// save keeps the loaded media, tags and authors
+	post.LoadData()
+
+	before := post.AuditFields()
+
+	err = revision.Restore(post, getAuthenticatedUserID(ctx))
+	if err != nil {
+		return errors.Wrap(err, "BlogPostRevisionController.Restore error on save blog post")
+	}
+
+	auditBlogPost(NewBlogAuditActorFromCtx(ctx), BlogAuditActionUpdate, post, before, post.AuditFields())
+
+	logrus.WithFields(logrus.Fields{
+		"id":         post.ID,
+		"revisionId": revision.ID,
+	}).Info("BlogPostRevisionController.Restore blog post revision restored")
+
+	resp := BlogPostFindOneJSONResponse{
+		Record: post,
+	}
+
+	return c.JSON(http.StatusOK, &resp)
+}
+
+// findRevisionsBlogPost - Find the blog post from route param and check if the user can update it
+func findRevisionsBlogPost(c echo.Context) (*BlogPostModel, error) {
+	ctx := c.(*catu.RequestContext)
+
+	var post BlogPostModel
+	err := BlogPostFindOne(c.Param("id"), &post)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return nil, errors.Wrap(err, "error on find blog post") // unknow error
+		}
+		return nil, &catu.HTTPError{
+			Code:     404,
+			Message:  "blog post not found",
+			Internal: err,
+		}
+	}
+
+	if !CanManageBlogPosts(ctx, "update_blog-post", post.BlogID) {
+		return nil, echo.NewHTTPError(http.StatusForbidden, "Forbidden")
+	}
+
+	return &post, nil
+}
+
+func findBlogPostRevision(post *BlogPostModel, id string) (*BlogPostRevisionModel, error) {
+	var record BlogPostRevisionModel
+	err := BlogPostRevisionFindOne(post.GetIDString(), id, &record)
+	if err != nil {
+		if err != gorm.ErrRecordNotFound {
+			return nil, errors.Wrap(err, "error on find blog post revision") // unknow error
+		}
+		return nil, &catu.HTTPError{
+			Code:     404,
+			Message:  "blog post revision not found",
+			Internal: err,
+		}
+	}
+
+	return &record, nil
+}
+
+type BlogPostRevisionControllerCfg struct {
+	App catu.App
+}
+
+func NewBlogPostRevisionController(cfg *BlogPostRevisionControllerCfg) *BlogPostRevisionController {
+	ctx := BlogPostRevisionController{App: cfg.App}
+
+	return &ctx
+}
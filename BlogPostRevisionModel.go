@@ -0,0 +1,118 @@
+package blog
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/go-catupiry/catu"
+)
+
+// BlogPostRevisionModel Stores one snapshot of blog post content, created on each save
+type BlogPostRevisionModel struct {
+	ID         uint64    `gorm:"primaryKey;column:id" json:"id"`
+	BlogPostID uint64    `gorm:"index:blogPostId;column:blogPostId;not null" json:"blogPostId"`
+	Title      string    `gorm:"column:title;type:varchar(255);not null" json:"title"`
+	Teaser     string    `gorm:"column:teaser;type:text" json:"teaser"`
+	Body       string    `gorm:"column:body;type:text" json:"body"`
+	BodyFormat string    `gorm:"column:bodyFormat;type:varchar(20);not null;default:html" json:"bodyFormat"`
+	AuthorID   *uint64   `gorm:"index:authorId;column:authorId" json:"authorId,string"`
+	CreatedAt  time.Time `gorm:"column:createdAt;type:datetime;not null" json:"createdAt"`
+}
+
+// TableName get sql table name
+func (m *BlogPostRevisionModel) TableName() string {
+	return "blog_post_revisions"
+}
+
+func (m *BlogPostRevisionModel) GetIDString() string {
+	return strconv.FormatUint(m.ID, 10)
+}
+
+func (m *BlogPostRevisionModel) Save() error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	if m.ID == 0 {
+		return db.Create(m).Error
+	}
+
+	return db.Save(m).Error
+}
+
+// HasSameContent - Check if the revision content is equal to the blog post content
+func (m *BlogPostRevisionModel) HasSameContent(post *BlogPostModel) bool {
+	return m.Title == post.Title && m.Teaser == post.Teaser && m.Body == post.Body && m.BodyFormat == post.BodyFormat
+}
+
+// Diff - Unified diff of title, teaser, body and body format between this revision and other one
+func (m *BlogPostRevisionModel) Diff(to *BlogPostRevisionModel) string {
+	fromName := "revision/" + m.GetIDString()
+	toName := "revision/" + to.GetIDString()
+
+	diff := ""
+	diff += unifiedDiff(fromName+"/title", toName+"/title", m.Title, to.Title)
+	diff += unifiedDiff(fromName+"/teaser", toName+"/teaser", m.Teaser, to.Teaser)
+	diff += unifiedDiff(fromName+"/body", toName+"/body", m.Body, to.Body)
+	diff += unifiedDiff(fromName+"/bodyFormat", toName+"/bodyFormat", m.BodyFormat, to.BodyFormat)
+
+	return diff
+}
+
+// Restore - Set the revision content in the blog post and save it. Use with posts loaded with LoadData
+func (m *BlogPostRevisionModel) Restore(post *BlogPostModel, userID *uint64) error {
+	post.Title = m.Title
+	post.Teaser = m.Teaser
+	post.Body = m.Body
+	post.BodyFormat = m.BodyFormat
+	post.UpdatedByID = userID
+
+	return post.Save()
+}
+
+func NewBlogPostRevision(post *BlogPostModel, authorID *uint64) *BlogPostRevisionModel {
+	return &BlogPostRevisionModel{
+		BlogPostID: post.ID,
+		Title:      post.Title,
+		Teaser:     post.Teaser,
+		Body:       post.Body,
+		BodyFormat: post.BodyFormat,
+		AuthorID:   authorID,
+	}
+}
+
+// BlogPostRevisionFindOne - Find one revision from the blog post
+func BlogPostRevisionFindOne(blogPostID, id string, record *BlogPostRevisionModel) error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	return db.
+		Where("blogPostId = ? AND id = ?", blogPostID, id).
+		First(record).Error
+}
+
+// BlogPostRevisionFindLatest - Find the latest revision from the blog post, record ID will be 0 if not found
+func BlogPostRevisionFindLatest(blogPostID string, record *BlogPostRevisionModel) error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	return db.
+		Where("blogPostId = ?", blogPostID).
+		Order("id DESC").
+		Limit(1).
+		Find(record).Error
+}
+
+// BlogPostRevisionFindMany - Find all revisions from the blog post, newest first
+func BlogPostRevisionFindMany(blogPostID string, records *[]*BlogPostRevisionModel) error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	return db.
+		Where("blogPostId = ?", blogPostID).
+		Order("id DESC").
+		Find(records).Error
+}
+
+func BlogPostRevisionDeleteAll(blogPostID string) error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	return db.
+		Where("blogPostId = ?", blogPostID).
+		Delete(&BlogPostRevisionModel{}).Error
+}
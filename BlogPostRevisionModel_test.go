@@ -0,0 +1,120 @@
+package blog
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/go-catupiry/files"
+	"gorm.io/gorm"
+)
+
+func createTestBlogPost(t *testing.T, db *gorm.DB, post *BlogPostModel) {
+	err := db.Create(post).Error
+	if err != nil {
+		t.Fatalf("error on create blog post: %v", err)
+	}
+}
+
+func createTestFeaturedImage(t *testing.T, db *gorm.DB, postID uint64) {
+	err := db.Exec(`INSERT INTO images (id, name, urls) VALUES (1, 'cover.jpg', '{"original":"/cover.jpg"}')`).Error
+	if err != nil {
+		t.Fatalf("error on create image: %v", err)
+	}
+
+	err = db.Create(&files.ImageAssocsModel{ModelName: "blog-post", ModelID: int64(postID), Field: "featuredImage", ImageID: 1}).Error
+	if err != nil {
+		t.Fatalf("error on create image assoc: %v", err)
+	}
+}
+
+func countTestFeaturedImages(t *testing.T, postID uint64) int {
+	images, err := files.GetImagesInField("blog-post", "featuredImage", strconv.FormatUint(postID, 10), 10)
+	if err != nil {
+		t.Fatalf("error on find featured image: %v", err)
+	}
+
+	return len(images)
+}
+
+func TestBlogPostRevisionRestore(t *testing.T) {
+	db := newTestDB(t)
+
+	post := BlogPostModel{ID: 1, Title: "New title", Body: "new body", BodyFormat: BlogPostBodyFormatHTML}
+	createTestBlogPost(t, db, &post)
+	createTestFeaturedImage(t, db, post.ID)
+
+	revision := BlogPostRevisionModel{ID: 1, BlogPostID: post.ID, Title: "Old title", Teaser: "old teaser", Body: "# Old body", BodyFormat: BlogPostBodyFormatMarkdown}
+	err := revision.Save()
+	if err != nil {
+		t.Fatalf("error on create revision: %v", err)
+	}
+
+	var record BlogPostModel
+	err = BlogPostFindOne("1", &record)
+	if err != nil {
+		t.Fatalf("error on find blog post: %v", err)
+	}
+	record.LoadData()
+
+	userID := uint64(3)
+	err = revision.Restore(&record, &userID)
+	if err != nil {
+		t.Fatalf("error on restore revision: %v", err)
+	}
+
+	var saved BlogPostModel
+	err = BlogPostFindOne("1", &saved)
+	if err != nil {
+		t.Fatalf("error on find restored blog post: %v", err)
+	}
+
+	if saved.Title != "Old title" || saved.Teaser != "old teaser" || saved.Body != "# Old body" {
+		t.Errorf("content not restored, got %q %q %q", saved.Title, saved.Teaser, saved.Body)
+	}
+
+	if saved.BodyFormat != BlogPostBodyFormatMarkdown {
+		t.Errorf("body format = %q, want %q", saved.BodyFormat, BlogPostBodyFormatMarkdown)
+	}
+
+	if saved.UpdatedByID == nil || *saved.UpdatedByID != userID {
+		t.Errorf("updatedById = %v, want %d", saved.UpdatedByID, userID)
+	}
+
+	if n := countTestFeaturedImages(t, post.ID); n != 1 {
+		t.Errorf("featured images after restore = %d, want 1", n)
+	}
+}
+
+func TestBlogPostSaveKeepsFeaturedImageIfNotLoaded(t *testing.T) {
+	db := newTestDB(t)
+
+	post := BlogPostModel{ID: 1, Title: "Title", Body: "body"}
+	createTestBlogPost(t, db, &post)
+	createTestFeaturedImage(t, db, post.ID)
+
+	var record BlogPostModel
+	err := BlogPostFindOne("1", &record)
+	if err != nil {
+		t.Fatalf("error on find blog post: %v", err)
+	}
+
+	record.Title = "Other title"
+	err = record.Save()
+	if err != nil {
+		t.Fatalf("error on save blog post: %v", err)
+	}
+
+	if n := countTestFeaturedImages(t, post.ID); n != 1 {
+		t.Errorf("featured images after save = %d, want 1", n)
+	}
+
+	record.FeaturedImage = []*files.ImageModel{}
+	err = record.Save()
+	if err != nil {
+		t.Fatalf("error on save blog post: %v", err)
+	}
+
+	if n := countTestFeaturedImages(t, post.ID); n != 0 {
+		t.Errorf("featured images after remove = %d, want 0", n)
+	}
+}
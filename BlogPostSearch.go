@@ -0,0 +1,388 @@
+package blog
+
+import (
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/go-catupiry/catu"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BlogPostSearchBackend - Full text search implementation used to filter blog posts with the `q` query param
+type BlogPostSearchBackend interface {
+	// Name - backend name used in the BLOG_SEARCH_BACKEND configuration
+	Name() string
+	// Setup - Create the indexes or tables used by the backend, runs in the migrate event
+	Setup(db *gorm.DB) error
+	// Available - Check if the backend can be used with the current database
+	Available(db *gorm.DB) bool
+	// Index - Add or update one blog post in the search index
+	Index(record *BlogPostModel) error
+	// Remove - Remove one blog post from the search index
+	Remove(id uint64) error
+	// Filter - Set the search condition in the blog post query
+	Filter(query *gorm.DB, q string) *gorm.DB
+	// Relevance - Order expression from most to less relevant, nil if the backend don't rank results
+	Relevance(q string) *clause.Expr
+}
+
+var blogPostSearchBackends = map[string]BlogPostSearchBackend{}
+var blogPostSearchBackend BlogPostSearchBackend
+
+func init() {
+	RegisterBlogPostSearchBackend(&LikeSearchBackend{})
+	RegisterBlogPostSearchBackend(&MySQLSearchBackend{})
+	RegisterBlogPostSearchBackend(&SQLiteSearchBackend{})
+}
+
+// RegisterBlogPostSearchBackend - Add one search backend, selectable with the BLOG_SEARCH_BACKEND configuration
+func RegisterBlogPostSearchBackend(backend BlogPostSearchBackend) {
+	blogPostSearchBackends[backend.Name()] = backend
+}
+
+// GetBlogPostSearchBackend - Get the current search backend, defaults to the LIKE backend
+func GetBlogPostSearchBackend() BlogPostSearchBackend {
+	if blogPostSearchBackend == nil {
+		return blogPostSearchBackends["like"]
+	}
+
+	return blogPostSearchBackend
+}
+
+// getConfiguredBlogPostSearchBackend - Get the backend from BLOG_SEARCH_BACKEND or from the database dialector name
+func getConfiguredBlogPostSearchBackend(app catu.App) BlogPostSearchBackend {
+	name := app.GetConfiguration().Get("BLOG_SEARCH_BACKEND")
+	if name == "" {
+		name = app.GetDB().Dialector.Name()
+	}
+
+	backend, ok := blogPostSearchBackends[name]
+	if !ok {
+		return blogPostSearchBackends["like"]
+	}
+
+	return backend
+}
+
+// SelectBlogPostSearchBackend - Set the search backend used in queries. Fallback to LIKE if the configured one is not available
+func SelectBlogPostSearchBackend(app catu.App) BlogPostSearchBackend {
+	backend := getConfiguredBlogPostSearchBackend(app)
+
+	if !backend.Available(app.GetDB()) {
+		logrus.WithFields(logrus.Fields{
+			"backend": backend.Name(),
+		}).Warn("SelectBlogPostSearchBackend backend not available, run the migrations. Using like")
+
+		backend = blogPostSearchBackends["like"]
+	}
+
+	blogPostSearchBackend = backend
+
+	return backend
+}
+
+// LikeSearchBackend - Search with LIKE in all text columns, works in any database but don't rank the results
+type LikeSearchBackend struct{}
+
+func (b *LikeSearchBackend) Name() string {
+	return "like"
+}
+
+func (b *LikeSearchBackend) Setup(db *gorm.DB) error {
+	return nil
+}
+
+func (b *LikeSearchBackend) Available(db *gorm.DB) bool {
+	return true
+}
+
+func (b *LikeSearchBackend) Index(record *BlogPostModel) error {
+	return nil
+}
+
+func (b *LikeSearchBackend) Remove(id uint64) error {
+	return nil
+}
+
+func (b *LikeSearchBackend) Filter(query *gorm.DB, q string) *gorm.DB {
+	like := "%" + q + "%"
+	return query.Where("(title LIKE ? OR teaser LIKE ? OR body LIKE ? OR searchTags LIKE ?)", like, like, like, like)
+}
+
+func (b *LikeSearchBackend) Relevance(q string) *clause.Expr {
+	return nil
+}
+
+// MySQLSearchBackend - Search with a MySQL FULLTEXT index in natural language mode
+type MySQLSearchBackend struct{}
+
+const blogPostFullTextIndex = "blog_posts_search"
+const blogPostFullTextMatch = "MATCH(title, teaser, body, searchTags) AGAINST (? IN NATURAL LANGUAGE MODE)"
+
+func (b *MySQLSearchBackend) Name() string {
+	return "mysql"
+}
+
+func (b *MySQLSearchBackend) Setup(db *gorm.DB) error {
+	if b.Available(db) {
+		return nil
+	}
+
+	err := db.Exec("CREATE FULLTEXT INDEX " + blogPostFullTextIndex + " ON blog_posts (title, teaser, body, searchTags)").Error
+	if err != nil {
+		return errors.Wrap(err, "MySQLSearchBackend.Setup error on create fulltext index")
+	}
+
+	return nil
+}
+
+func (b *MySQLSearchBackend) Available(db *gorm.DB) bool {
+	return db.Dialector.Name() == "mysql" && db.Migrator().HasIndex(&BlogPostModel{}, blogPostFullTextIndex)
+}
+
+// Index - the FULLTEXT index is updated by the database
+func (b *MySQLSearchBackend) Index(record *BlogPostModel) error {
+	return nil
+}
+
+func (b *MySQLSearchBackend) Remove(id uint64) error {
+	return nil
+}
+
+func (b *MySQLSearchBackend) Filter(query *gorm.DB, q string) *gorm.DB {
+	return query.Where(blogPostFullTextMatch, q)
+}
+
+func (b *MySQLSearchBackend) Relevance(q string) *clause.Expr {
+	return &clause.Expr{SQL: blogPostFullTextMatch + " DESC", Vars: []interface{}{q}}
+}
+
+// SQLiteSearchBackend - Search with a SQLite FTS5 virtual table, requires the sqlite_fts5 build tag in go-sqlite3
+type SQLiteSearchBackend struct{}
+
+const blogPostFTSTable = "blog_posts_fts"
+
+func (b *SQLiteSearchBackend) Name() string {
+	return "sqlite"
+}
+
+func (b *SQLiteSearchBackend) Setup(db *gorm.DB) error {
+	if db.Migrator().HasTable(blogPostFTSTable) {
+		return nil
+	}
+
+	err := db.Exec("CREATE VIRTUAL TABLE " + blogPostFTSTable + " USING fts5(title, teaser, body, searchTags)").Error
+	if err != nil {
+		return errors.Wrap(err, "SQLiteSearchBackend.Setup error on create fts5 table")
+	}
+
+	// index the old records:
+	err = db.Exec("INSERT INTO " + blogPostFTSTable + "(rowid, title, teaser, body, searchTags) " +
+		"SELECT id, title, teaser, body, searchTags FROM blog_posts").Error
+	if err != nil {
+		return errors.Wrap(err, "SQLiteSearchBackend.Setup error on index blog posts")
+	}
+
+	return nil
+}
+
+func (b *SQLiteSearchBackend) Available(db *gorm.DB) bool {
+	return db.Dialector.Name() == "sqlite" && db.Migrator().HasTable(blogPostFTSTable)
+}
+
+func (b *SQLiteSearchBackend) Index(record *BlogPostModel) error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec("DELETE FROM "+blogPostFTSTable+" WHERE rowid = ?", record.ID).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec("INSERT INTO "+blogPostFTSTable+"(rowid, title, teaser, body, searchTags) VALUES (?, ?, ?, ?, ?)",
+			record.ID, record.Title, record.Teaser, record.Body, record.SearchTags).Error
+	})
+}
+
+func (b *SQLiteSearchBackend) Remove(id uint64) error {
+	db := catu.GetDefaultDatabaseConnection()
+	return db.Exec("DELETE FROM "+blogPostFTSTable+" WHERE rowid = ?", id).Error
+}
+
+func (b *SQLiteSearchBackend) Filter(query *gorm.DB, q string) *gorm.DB {
+	return query.Where("blog_posts.id IN (SELECT rowid FROM "+blogPostFTSTable+" WHERE "+blogPostFTSTable+" MATCH ?)", ftsMatchQuery(q))
+}
+
+// Relevance - bm25 returns lower values for better matches
+func (b *SQLiteSearchBackend) Relevance(q string) *clause.Expr {
+	return &clause.Expr{
+		SQL:  "(SELECT bm25(" + blogPostFTSTable + ") FROM " + blogPostFTSTable + " WHERE " + blogPostFTSTable + " MATCH ? AND rowid = blog_posts.id) ASC",
+		Vars: []interface{}{ftsMatchQuery(q)},
+	}
+}
+
+// ftsMatchQuery - Quote each term to avoid FTS5 syntax errors with user input, terms are joined with AND
+func ftsMatchQuery(q string) string {
+	terms := strings.Fields(q)
+	for i := range terms {
+		terms[i] = `"` + strings.ReplaceAll(terms[i], `"`, `""`) + `"`
+	}
+
+	return strings.Join(terms, " ")
+}
+
+// setBlogPostSearchQuery - Set the search filter in the query, with relevance order if order is true
+func setBlogPostSearchQuery(query *gorm.DB, q string, order bool) *gorm.DB {
+	backend := GetBlogPostSearchBackend()
+
+	query = backend.Filter(query, q)
+
+	if order {
+		relevance := backend.Relevance(q)
+		if relevance != nil {
+			// order by clauses are replaced with the expression, so add the default order after relevance:
+			query = query.Clauses(clause.OrderBy{
+				Expression: clause.Expr{
+					SQL:  relevance.SQL + ", highlighted DESC, publishedAt DESC, id DESC",
+					Vars: relevance.Vars,
+				},
+			})
+		}
+	}
+
+	return query
+}
+
+// indexBlogPost - Update the blog post in the search backend, errors are only logged
+func indexBlogPost(record *BlogPostModel) {
+	err := GetBlogPostSearchBackend().Index(record)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"id":    record.ID,
+			"error": err,
+		}).Error("indexBlogPost error on index blog post")
+	}
+}
+
+// removeBlogPostFromIndex - Remove the blog post from the search backend, errors are only logged
+func removeBlogPostFromIndex(id uint64) {
+	err := GetBlogPostSearchBackend().Remove(id)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"id":    id,
+			"error": err,
+		}).Error("removeBlogPostFromIndex error on remove blog post")
+	}
+}
+
+const searchSnippetBefore = 60
+const searchSnippetLength = 200
+
+var searchSnippetPolicy = bluemonday.StrictPolicy()
+
+// BuildSearchSnippet - Text fragment around the first query term, HTML escaped and with the terms inside <mark> tags
+func BuildSearchSnippet(content, q string) string {
+	text := []rune(strings.Join(strings.Fields(html.UnescapeString(searchSnippetPolicy.Sanitize(content))), " "))
+	// lower case rune by rune to keep the same positions of the text
+	lower := make([]rune, len(text))
+	for i := range text {
+		lower[i] = unicode.ToLower(text[i])
+	}
+
+	terms := [][]rune{}
+	for _, t := range strings.Fields(q) {
+		term := []rune(t)
+		for i := range term {
+			term[i] = unicode.ToLower(term[i])
+		}
+		terms = append(terms, term)
+	}
+
+	if len(text) == 0 || len(terms) == 0 {
+		return ""
+	}
+
+	matchPos := 0
+	for i := range lower {
+		if matchSnippetTerm(lower, i, terms) > 0 {
+			matchPos = i
+			break
+		}
+	}
+
+	start := matchPos - searchSnippetBefore
+	if start < 0 {
+		start = 0
+	}
+	// start in a word boundary:
+	for start > 0 && start < matchPos && !unicode.IsSpace(text[start-1]) {
+		start++
+	}
+
+	end := start + searchSnippetLength
+	if end > len(text) {
+		end = len(text)
+	}
+
+	var out strings.Builder
+	if start > 0 {
+		out.WriteString("…")
+	}
+
+	plainStart := start
+	for i := start; i < end; {
+		size := matchSnippetTerm(lower, i, terms)
+		if size == 0 {
+			i++
+			continue
+		}
+
+		out.WriteString(html.EscapeString(string(text[plainStart:i])))
+		out.WriteString("<mark>" + html.EscapeString(string(text[i:i+size])) + "</mark>")
+		i += size
+		plainStart = i
+	}
+
+	if plainStart < end {
+		out.WriteString(html.EscapeString(string(text[plainStart:end])))
+	}
+
+	if end < len(text) {
+		out.WriteString("…")
+	}
+
+	return out.String()
+}
+
+// matchSnippetTerm - Size of the term found in the text position or 0
+func matchSnippetTerm(text []rune, pos int, terms [][]rune) int {
+	for _, term := range terms {
+		if pos+len(term) > len(text) {
+			continue
+		}
+
+		found := true
+		for j := range term {
+			if text[pos+j] != term[j] {
+				found = false
+				break
+			}
+		}
+
+		if found {
+			return len(term)
+		}
+	}
+
+	return 0
+}
+
+// LoadSearchSnippet - Set the SearchSnippet with the matched text from teaser or body
+func (r *BlogPostModel) LoadSearchSnippet(q string) {
+	r.SearchSnippet = BuildSearchSnippet(r.Teaser+" "+r.Body, q)
+}
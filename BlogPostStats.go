@@ -0,0 +1,374 @@
+package blog
+
+import (
+	"bytes"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-catupiry/catu"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// same visitor views in this interval are counted once
+const blogPostViewDedupInterval = 30 * time.Minute
+
+// max visitors in the dedup map, the expired ones are removed when it is full
+const blogPostViewSeenLimit = 100000
+
+// buffered views that start one flush before the cron-job
+const blogPostViewFlushThreshold = 1000
+
+// posts in the most read block
+const mostReadBlockLimit = 5
+
+// BlogPostStatModel Stores the blog post views count per day
+type BlogPostStatModel struct {
+	ID         uint64    `gorm:"primaryKey;column:id" json:"id"`
+	BlogPostID uint64    `gorm:"uniqueIndex:blogPostStatPostDay;column:blogPostId;not null" json:"blogPostId"`
+	Day        time.Time `gorm:"uniqueIndex:blogPostStatPostDay;index:blogPostStatDay;column:day;type:date;not null" json:"day"`
+	Views      int64     `gorm:"column:views;not null;default:0" json:"views"`
+}
+
+// TableName get sql table name
+func (m *BlogPostStatModel) TableName() string {
+	return "blog_post_stats"
+}
+
+// bot user agents parts, checked in lowercase
+var botUserAgentParts = []string{
+	"bot", "crawl", "spider", "slurp", "archiver", "facebookexternalhit", "embedly",
+	"preview", "monitor", "headless", "lighthouse", "curl", "wget", "python", "java/",
+	"go-http-client", "okhttp", "axios", "node-fetch", "httpclient", "scrapy", "feed",
+}
+
+// IsBotUserAgent - Check if the User-Agent is empty or looks like one bot, crawler or http library
+func IsBotUserAgent(ua string) bool {
+	ua = strings.ToLower(strings.TrimSpace(ua))
+	if ua == "" || (!strings.Contains(ua, "mozilla") && !strings.Contains(ua, "opera")) {
+		return true
+	}
+
+	for _, part := range botUserAgentParts {
+		if strings.Contains(ua, part) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type blogPostViewKey struct {
+	BlogPostID uint64
+	Day        time.Time
+}
+
+// BlogPostViewCounter - Buffer the blog post views in memory, saved in the database with Flush
+type BlogPostViewCounter struct {
+	mu     sync.Mutex
+	counts map[blogPostViewKey]int64
+	// sum of counts
+	pending int64
+	// last view time by post, IP and user agent hash
+	seen     map[string]time.Time
+	flushing bool
+	// clock, defaults to time.Now
+	Now func() time.Time
+}
+
+func NewBlogPostViewCounter() *BlogPostViewCounter {
+	return &BlogPostViewCounter{
+		counts: map[blogPostViewKey]int64{},
+		seen:   map[string]time.Time{},
+		Now:    time.Now,
+	}
+}
+
+var blogPostViews = NewBlogPostViewCounter()
+
+// Add - Count one view of the post. Bots and repeated views from the same visitor are skipped.
+// Returns true if the view was counted
+func (c *BlogPostViewCounter) Add(blogPostID uint64, visitor, userAgent string) bool {
+	if IsBotUserAgent(userAgent) {
+		return false
+	}
+
+	now := c.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	seenKey := blogPostViewSeenKey(blogPostID, visitor, userAgent)
+	if last, ok := c.seen[seenKey]; ok && now.Sub(last) < blogPostViewDedupInterval {
+		return false
+	}
+
+	if len(c.seen) >= blogPostViewSeenLimit {
+		c.pruneSeen(now)
+	}
+	c.seen[seenKey] = now
+
+	c.counts[blogPostViewKey{BlogPostID: blogPostID, Day: statDay(now)}]++
+	c.pending++
+
+	return true
+}
+
+// blogPostViewSeenKey - Dedup key with the user agent hash, the raw user agent can be long
+func blogPostViewSeenKey(blogPostID uint64, visitor, userAgent string) string {
+	h := fnv.New64a()
+	h.Write([]byte(userAgent))
+
+	return strconv.FormatUint(blogPostID, 10) + "|" + visitor + "|" + strconv.FormatUint(h.Sum64(), 16)
+}
+
+// pruneSeen - Remove the expired visitors, or all if the map is still full
+func (c *BlogPostViewCounter) pruneSeen(now time.Time) {
+	for key, last := range c.seen {
+		if now.Sub(last) >= blogPostViewDedupInterval {
+			delete(c.seen, key)
+		}
+	}
+
+	if len(c.seen) >= blogPostViewSeenLimit {
+		c.seen = map[string]time.Time{}
+	}
+}
+
+// shouldFlush - Check if the buffer is over the threshold and no flush is running
+func (c *BlogPostViewCounter) shouldFlush() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return !c.flushing && c.pending >= blogPostViewFlushThreshold
+}
+
+// Pending - Number of buffered views
+func (c *BlogPostViewCounter) Pending() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.pending
+}
+
+// Flush - Save the buffered views in one transaction. Views are kept in the buffer if it fails.
+// Only one flush runs at a time, concurrent calls return without changes and the views are saved in the next flush
+func (c *BlogPostViewCounter) Flush(db *gorm.DB) error {
+	now := c.Now()
+
+	c.mu.Lock()
+	if c.flushing {
+		c.mu.Unlock()
+		return nil
+	}
+	c.flushing = true
+
+	counts := c.counts
+	c.counts = map[blogPostViewKey]int64{}
+	c.pending = 0
+
+	for key, last := range c.seen {
+		if now.Sub(last) >= blogPostViewDedupInterval {
+			delete(c.seen, key)
+		}
+	}
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		c.flushing = false
+		c.mu.Unlock()
+	}()
+
+	if len(counts) == 0 {
+		return nil
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for key, n := range counts {
+			err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "blogPostId"}, {Name: "day"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{"views": gorm.Expr("views + ?", n)}),
+			}).Create(&BlogPostStatModel{
+				BlogPostID: key.BlogPostID,
+				Day:        key.Day,
+				Views:      n,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		c.mu.Lock()
+		for key, n := range counts {
+			c.counts[key] += n
+			c.pending += n
+		}
+		c.mu.Unlock()
+
+		return err
+	}
+
+	return nil
+}
+
+// statDay - Stats are grouped by UTC day
+func statDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// RegisterBlogPostView - Count the blog post page view from the request
+func RegisterBlogPostView(ctx *catu.RequestContext, record *BlogPostModel) bool {
+	if !record.Published {
+		return false
+	}
+
+	counted := blogPostViews.Add(record.ID, ctx.RealIP(), ctx.Request().UserAgent())
+
+	if counted && blogPostViews.shouldFlush() {
+		go func() {
+			err := FlushBlogPostViews()
+			if err != nil {
+				logrus.WithFields(logrus.Fields{
+					"error": err,
+				}).Error("RegisterBlogPostView error on flush blog post views")
+			}
+		}()
+	}
+
+	return counted
+}
+
+// FlushBlogPostViews - Save the buffered blog post views, used in the cron-job and close events
+func FlushBlogPostViews() error {
+	return blogPostViews.Flush(catu.GetDefaultDatabaseConnection())
+}
+
+// BlogPostStatsDeleteAll - Delete the post views stats
+func BlogPostStatsDeleteAll(blogPostID string) error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	return db.
+		Where("blogPostId = ?", blogPostID).
+		Delete(&BlogPostStatModel{}).Error
+}
+
+type blogPostViewsSum struct {
+	BlogPostID uint64
+	TotalViews int64
+}
+
+// BlogPostFindMostRead - Find the published posts with more views in the last days, use blogID nil for all blogs
+func BlogPostFindMostRead(blogID *uint64, days, limit int, now time.Time, records *[]*BlogPostModel) error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	query := db.
+		Table("blog_post_stats").
+		Select("blog_post_stats.blogPostId AS blog_post_id, SUM(blog_post_stats.views) AS total_views").
+		Joins("INNER JOIN blog_posts ON blog_posts.id = blog_post_stats.blogPostId").
+		Where("blog_post_stats.day >= ?", statDay(now).AddDate(0, 0, -days+1)).
+		Where("blog_posts.published = ?", true)
+
+	if blogID != nil {
+		query = query.Where("blog_posts.blogId = ?", *blogID)
+	}
+
+	sums := []blogPostViewsSum{}
+	err := query.
+		Group("blog_post_stats.blogPostId").
+		Order("total_views DESC").
+		Order("blog_post_id DESC").
+		Limit(limit).
+		Scan(&sums).Error
+	if err != nil {
+		return err
+	}
+
+	*records = []*BlogPostModel{}
+
+	if len(sums) == 0 {
+		return nil
+	}
+
+	ids := []uint64{}
+	for _, s := range sums {
+		ids = append(ids, s.BlogPostID)
+	}
+
+	posts := []*BlogPostModel{}
+	err = db.Where("id IN ?", ids).Find(&posts).Error
+	if err != nil {
+		return err
+	}
+
+	byID := map[uint64]*BlogPostModel{}
+	for _, p := range posts {
+		byID[p.ID] = p
+	}
+
+	for _, s := range sums {
+		if p, ok := byID[s.BlogPostID]; ok {
+			p.Views = s.TotalViews
+			*records = append(*records, p)
+		}
+	}
+
+	return nil
+}
+
+// getMostReadDays - Days used in the most read block, BLOG_MOST_READ_DAYS configuration (default 7)
+func getMostReadDays() int {
+	days, _ := strconv.Atoi(catu.GetConfiguration().GetF("BLOG_MOST_READ_DAYS", "7"))
+	if days <= 0 {
+		days = 7
+	}
+
+	return days
+}
+
+// LoadMostReadBlockData - Render the most read posts teasers in the "mostReadPosts" context variable
+func LoadMostReadBlockData(ctx *catu.RequestContext, blogID *uint64) error {
+	records := []*BlogPostModel{}
+	err := BlogPostFindMostRead(blogID, getMostReadDays(), mostReadBlockLimit, time.Now(), &records)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"err": err,
+		}).Error("LoadMostReadBlockData error on find most read posts")
+		return err
+	}
+
+	var teaserList []string
+
+	preloadBlogPostsBlogSlugs(records)
+
+	for i := range records {
+		records[i].LoadTeaserData()
+
+		var teaserHTML bytes.Buffer
+
+		err = ctx.RenderTemplate(&teaserHTML, "blog-post/teaser", BlogPostTeaserTPL{
+			Ctx:    ctx,
+			Record: records[i],
+		})
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"err": err.Error(),
+			}).Error("LoadMostReadBlockData error on render teaser")
+		} else {
+			teaserList = append(teaserList, teaserHTML.String())
+		}
+	}
+
+	ctx.Set("hasMostReadPosts", len(teaserList) > 0)
+	ctx.Set("mostReadPosts", teaserList)
+
+	return nil
+}
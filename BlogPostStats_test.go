@@ -0,0 +1,58 @@
+package blog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBlogPostViewCounterFlush(t *testing.T) {
+	db := newTestDB(t)
+
+	err := db.Migrator().CreateTable(&BlogPostStatModel{})
+	if err != nil {
+		t.Fatalf("error on create blog post stats table: %v", err)
+	}
+
+	c := NewBlogPostViewCounter()
+	c.Now = func() time.Time { return time.Date(2022, 9, 14, 10, 0, 0, 0, time.UTC) }
+
+	c.Add(1, "10.0.0.1", "Mozilla/5.0")
+	c.Add(1, "10.0.0.2", "Mozilla/5.0")
+
+	// other flush is running
+	c.flushing = true
+
+	err = c.Flush(db)
+	if err != nil {
+		t.Fatalf("error on flush: %v", err)
+	}
+
+	if c.Pending() != 2 {
+		t.Errorf("pending views while other flush runs = %d, want 2", c.Pending())
+	}
+
+	if c.shouldFlush() {
+		t.Error("shouldFlush should be false while other flush runs")
+	}
+
+	c.flushing = false
+
+	err = c.Flush(db)
+	if err != nil {
+		t.Fatalf("error on flush: %v", err)
+	}
+
+	if c.Pending() != 0 || c.flushing {
+		t.Errorf("pending = %d and flushing = %v after flush, want 0 and false", c.Pending(), c.flushing)
+	}
+
+	var stat BlogPostStatModel
+	err = db.Where("blogPostId = ?", 1).First(&stat).Error
+	if err != nil {
+		t.Fatalf("error on find blog post stat: %v", err)
+	}
+
+	if stat.Views != 2 {
+		t.Errorf("saved views = %d, want 2", stat.Views)
+	}
+}
@@ -0,0 +1,172 @@
+package blog
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-catupiry/catu"
+	"github.com/gookit/event"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Blog post editorial workflow states
+const (
+	BlogPostStatusDraft     = "draft"
+	BlogPostStatusInReview  = "in_review"
+	BlogPostStatusApproved  = "approved"
+	BlogPostStatusScheduled = "scheduled"
+	BlogPostStatusPublished = "published"
+	BlogPostStatusArchived  = "archived"
+)
+
+// BlogPostTransition one allowed change of blog post status and the permission required to run it
+type BlogPostTransition struct {
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Permission string `json:"permission"`
+}
+
+var blogPostTransitions = []BlogPostTransition{
+	// send to review
+	{From: BlogPostStatusDraft, To: BlogPostStatusInReview, Permission: "update_blog-post"},
+	// reviewer sign-off
+	{From: BlogPostStatusInReview, To: BlogPostStatusApproved, Permission: "review_blog-post"},
+	{From: BlogPostStatusInReview, To: BlogPostStatusDraft, Permission: "review_blog-post"},
+	{From: BlogPostStatusApproved, To: BlogPostStatusDraft, Permission: "review_blog-post"},
+	// publication
+	{From: BlogPostStatusApproved, To: BlogPostStatusScheduled, Permission: "publish_blog-post"},
+	{From: BlogPostStatusApproved, To: BlogPostStatusPublished, Permission: "publish_blog-post"},
+	{From: BlogPostStatusScheduled, To: BlogPostStatusApproved, Permission: "publish_blog-post"},
+	{From: BlogPostStatusScheduled, To: BlogPostStatusPublished, Permission: "publish_blog-post"},
+	{From: BlogPostStatusPublished, To: BlogPostStatusArchived, Permission: "publish_blog-post"},
+	// reopen
+	{From: BlogPostStatusArchived, To: BlogPostStatusDraft, Permission: "update_blog-post"},
+}
+
+// FindBlogPostTransition - Get the transition between two status, returns nil if not allowed
+func FindBlogPostTransition(from, to string) *BlogPostTransition {
+	for i := range blogPostTransitions {
+		if blogPostTransitions[i].From == from && blogPostTransitions[i].To == to {
+			return &blogPostTransitions[i]
+		}
+	}
+
+	return nil
+}
+
+// IsBlogPostSelfApproval - Check if the transition is the approval of one post created by the user
+func IsBlogPostSelfApproval(t *BlogPostTransition, record *BlogPostModel, userID *uint64) bool {
+	return t.To == BlogPostStatusApproved &&
+		userID != nil &&
+		record.CreatorID != nil &&
+		uint64(*record.CreatorID) == *userID
+}
+
+// CanRunBlogPostTransition - Check the transition permission in the post blog. The reviewer sign-off must be
+// from other user, blog editors can't approve their own posts and only the global permission allows it
+func CanRunBlogPostTransition(ctx *catu.RequestContext, t *BlogPostTransition, record *BlogPostModel) bool {
+	if IsBlogPostSelfApproval(t, record, getAuthenticatedUserID(ctx)) {
+		return ctx.Can(t.Permission)
+	}
+
+	return CanManageBlogPosts(ctx, t.Permission, record.BlogID)
+}
+
+// GetBlogPostTransitionsFrom - Get all transitions available from one status
+func GetBlogPostTransitionsFrom(from string) []BlogPostTransition {
+	transitions := []BlogPostTransition{}
+
+	for i := range blogPostTransitions {
+		if blogPostTransitions[i].From == from {
+			transitions = append(transitions, blogPostTransitions[i])
+		}
+	}
+
+	return transitions
+}
+
+func IsValidBlogPostStatus(status string) bool {
+	switch status {
+	case BlogPostStatusDraft,
+		BlogPostStatusInReview,
+		BlogPostStatusApproved,
+		BlogPostStatusScheduled,
+		BlogPostStatusPublished,
+		BlogPostStatusArchived:
+		return true
+	}
+
+	return false
+}
+
+// ChangeStatus - Set the post status and the published fields, save it and fire the blog-post-state-changed event.
+// Transition rules are checked by the caller
+func (m *BlogPostModel) ChangeStatus(status string) error {
+	if !IsValidBlogPostStatus(status) {
+		return fmt.Errorf("invalid blog post status: %s", status)
+	}
+
+	from := m.Status
+	if from == status {
+		return nil
+	}
+
+	now := time.Now()
+
+	switch status {
+	case BlogPostStatusPublished:
+		m.Published = true
+		if m.PublishedAt == nil || m.PublishedAt.After(now) {
+			m.PublishedAt = &now
+		}
+		// a expired unpublish date would archive the post again in the next cron-job
+		if m.UnpublishAt != nil && !m.UnpublishAt.After(now) {
+			m.UnpublishAt = nil
+		}
+	case BlogPostStatusScheduled:
+		if m.PublishedAt == nil || !m.PublishedAt.After(now) {
+			return errors.New("scheduled blog posts requires a future publishedAt date")
+		}
+		m.Published = false
+	case BlogPostStatusArchived:
+		m.Published = false
+		m.PublishedAt = nil
+	default:
+		m.Published = false
+	}
+
+	m.Status = status
+
+	db := catu.GetDefaultDatabaseConnection()
+	err := db.Model(m).Updates(map[string]interface{}{
+		"status":      m.Status,
+		"published":   m.Published,
+		"publishedAt": m.PublishedAt,
+		"unpublishAt": m.UnpublishAt,
+		"updatedById": m.UpdatedByID,
+	}).Error
+	if err != nil {
+		return errors.Wrap(err, "error on change blog post status")
+	}
+
+	indexBlogPost(m)
+
+	app := catu.GetApp()
+	err, _ = app.GetEvents().Fire("blog-post-state-changed", event.M{
+		"app":    app,
+		"record": m,
+		"from":   from,
+		"to":     status,
+	})
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"id":    m.ID,
+			"from":  from,
+			"to":    status,
+			"error": fmt.Sprintf("%+v\n", err),
+		}).Error("BlogPostModel.ChangeStatus error on fire blog-post-state-changed event")
+	}
+
+	return nil
+}
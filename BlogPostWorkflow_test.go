@@ -0,0 +1,139 @@
+package blog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-catupiry/catu"
+)
+
+// testUser - Authenticated user with only the id, other methods are not used
+type testUser struct {
+	catu.UserInterface
+	id string
+}
+
+func (u *testUser) GetID() string {
+	return u.id
+}
+
+func newTestRequestContext(userID string, roles ...string) *catu.RequestContext {
+	return &catu.RequestContext{
+		App:               testApp,
+		IsAuthenticated:   true,
+		AuthenticatedUser: &testUser{id: userID},
+		Roles:             append(roles, "authenticated"),
+	}
+}
+
+func TestIsBlogPostSelfApproval(t *testing.T) {
+	creatorID := uint(5)
+	userID := uint64(5)
+	otherID := uint64(6)
+
+	approve := FindBlogPostTransition(BlogPostStatusInReview, BlogPostStatusApproved)
+	reject := FindBlogPostTransition(BlogPostStatusInReview, BlogPostStatusDraft)
+
+	tests := []struct {
+		name       string
+		transition *BlogPostTransition
+		creatorID  *uint
+		userID     *uint64
+		want       bool
+	}{
+		{"creator approves", approve, &creatorID, &userID, true},
+		{"other user approves", approve, &creatorID, &otherID, false},
+		{"post without creator", approve, nil, &userID, false},
+		{"anonymous user", approve, &creatorID, nil, false},
+		{"creator sends back to draft", reject, &creatorID, &userID, false},
+	}
+
+	for _, tt := range tests {
+		record := BlogPostModel{CreatorID: tt.creatorID, Status: BlogPostStatusInReview}
+		if got := IsBlogPostSelfApproval(tt.transition, &record, tt.userID); got != tt.want {
+			t.Errorf("%s: IsBlogPostSelfApproval = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCanRunBlogPostTransitionSelfApproval(t *testing.T) {
+	db := newTestDB(t)
+
+	err := db.Migrator().CreateTable(&BlogEditorsModel{})
+	if err != nil {
+		t.Fatalf("error on create blog editors table: %v", err)
+	}
+
+	blogID := uint64(1)
+	for _, editor := range []*BlogEditorsModel{
+		{BlogID: 1, UserID: 5, Role: BlogEditorRoleEditor},
+		{BlogID: 1, UserID: 6, Role: BlogEditorRoleEditor},
+	} {
+		err = editor.Save()
+		if err != nil {
+			t.Fatalf("error on save blog editor: %v", err)
+		}
+	}
+
+	creatorID := uint(5)
+	record := BlogPostModel{ID: 1, BlogID: &blogID, CreatorID: &creatorID, Status: BlogPostStatusInReview}
+
+	approve := FindBlogPostTransition(BlogPostStatusInReview, BlogPostStatusApproved)
+	reject := FindBlogPostTransition(BlogPostStatusInReview, BlogPostStatusDraft)
+
+	if CanRunBlogPostTransition(newTestRequestContext("5"), approve, &record) {
+		t.Error("blog editor should not approve the own post")
+	}
+
+	if !CanRunBlogPostTransition(newTestRequestContext("5"), reject, &record) {
+		t.Error("blog editor should send the own post back to draft")
+	}
+
+	if !CanRunBlogPostTransition(newTestRequestContext("6"), approve, &record) {
+		t.Error("other blog editor should approve the post")
+	}
+
+	if CanRunBlogPostTransition(newTestRequestContext("7"), approve, &record) {
+		t.Error("user without blog role should not approve the post")
+	}
+
+	if !CanRunBlogPostTransition(newTestRequestContext("5", "administrator"), approve, &record) {
+		t.Error("global reviewers can approve the own post")
+	}
+}
+
+func TestBlogPostPublishKeepsPublishedAt(t *testing.T) {
+	db := newTestDB(t)
+
+	scheduledAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	scheduled := BlogPostModel{ID: 1, Title: "Scheduled", Status: BlogPostStatusScheduled, PublishedAt: &scheduledAt}
+	createTestBlogPost(t, db, &scheduled)
+
+	draft := BlogPostModel{ID: 2, Title: "Draft", Status: BlogPostStatusDraft}
+	createTestBlogPost(t, db, &draft)
+
+	err := scheduled.Publish()
+	if err != nil {
+		t.Fatalf("error on publish scheduled blog post: %v", err)
+	}
+
+	var saved BlogPostModel
+	err = BlogPostFindOne("1", &saved)
+	if err != nil {
+		t.Fatalf("error on find blog post: %v", err)
+	}
+
+	if !saved.Published || saved.PublishedAt == nil || !saved.PublishedAt.Equal(scheduledAt) {
+		t.Errorf("publishedAt = %v, want %v", saved.PublishedAt, scheduledAt)
+	}
+
+	before := time.Now()
+	err = draft.Publish()
+	if err != nil {
+		t.Fatalf("error on publish draft blog post: %v", err)
+	}
+
+	if draft.PublishedAt == nil || draft.PublishedAt.Before(before) {
+		t.Errorf("publishedAt = %v, want the publication time", draft.PublishedAt)
+	}
+}
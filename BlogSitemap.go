@@ -0,0 +1,244 @@
+package blog
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strconv"
+	"time"
+
+	"github.com/go-catupiry/catu"
+	"github.com/go-catupiry/files"
+	"github.com/sirupsen/logrus"
+)
+
+// max urls in one sitemap file
+const sitemapURLLimit = 50000
+
+// max ids in one sitemap images query
+const sitemapImagesBatchSize = 1000
+
+// SitemapURLSet sitemap 0.9 document with the image extension
+type SitemapURLSet struct {
+	XMLName    xml.Name      `xml:"urlset"`
+	Xmlns      string        `xml:"xmlns,attr"`
+	XmlnsImage string        `xml:"xmlns:image,attr"`
+	URLs       []*SitemapURL `xml:"url"`
+}
+
+type SitemapURL struct {
+	Loc     string          `xml:"loc"`
+	LastMod string          `xml:"lastmod,omitempty"`
+	Images  []*SitemapImage `xml:"image:image"`
+}
+
+type SitemapImage struct {
+	Loc string `xml:"image:loc"`
+}
+
+// SitemapIndex sitemap index document, used if the urls don't fit in one file
+type SitemapIndex struct {
+	XMLName  xml.Name      `xml:"sitemapindex"`
+	Xmlns    string        `xml:"xmlns,attr"`
+	Sitemaps []*SitemapRef `xml:"sitemap"`
+}
+
+type SitemapRef struct {
+	Loc string `xml:"loc"`
+}
+
+// BlogSitemapCount - Number of urls in the sitemap, blogs in lists and published posts
+func BlogSitemapCount() (int64, error) {
+	db := catu.GetDefaultDatabaseConnection()
+
+	var blogs int64
+	err := db.Model(&BlogModel{}).
+		Where("show_in_lists = ?", true).
+		Count(&blogs).Error
+	if err != nil {
+		return 0, err
+	}
+
+	var posts int64
+	err = db.Model(&BlogPostModel{}).
+		Where("published = ? AND blogId IS NOT NULL", true).
+		Count(&posts).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return blogs + posts, nil
+}
+
+// NewSitemapIndex - Index with one sitemap page for each sitemapURLLimit urls
+func NewSitemapIndex(sitemapURL string, count int64) *SitemapIndex {
+	index := SitemapIndex{Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+
+	pages := (count + sitemapURLLimit - 1) / sitemapURLLimit
+	for page := int64(1); page <= pages; page++ {
+		index.Sitemaps = append(index.Sitemaps, &SitemapRef{
+			Loc: sitemapURL + "?page=" + strconv.FormatInt(page, 10),
+		})
+	}
+
+	return &index
+}
+
+// NewBlogSitemap - Sitemap with the urls in the page, blogs are listed before the posts. Page starts with 1
+func NewBlogSitemap(page int) (*SitemapURLSet, error) {
+	db := catu.GetDefaultDatabaseConnection()
+	origin := catu.GetConfiguration().Get("APP_ORIGIN")
+
+	sitemap := SitemapURLSet{
+		Xmlns:      "http://www.sitemaps.org/schemas/sitemap/0.9",
+		XmlnsImage: "http://www.google.com/schemas/sitemap-image/1.1",
+		URLs:       []*SitemapURL{},
+	}
+
+	offset := (page - 1) * sitemapURLLimit
+
+	var blogCount int64
+	err := db.Model(&BlogModel{}).
+		Where("show_in_lists = ?", true).
+		Count(&blogCount).Error
+	if err != nil {
+		return nil, err
+	}
+
+	blogs := []*BlogModel{}
+	if int64(offset) < blogCount {
+		err = db.
+			Omit("Editors").
+			Select("id", "urlUniquePath", "updatedAt").
+			Where("show_in_lists = ?", true).
+			Order("id ASC").
+			Offset(offset).
+			Limit(sitemapURLLimit).
+			Find(&blogs).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	postOffset := offset - int(blogCount)
+	if postOffset < 0 {
+		postOffset = 0
+	}
+
+	posts := []*BlogPostModel{}
+	if postLimit := sitemapURLLimit - len(blogs); postLimit > 0 {
+		err = db.
+			Select("id", "blogId", "urlPath", "updatedAt").
+			Where("published = ? AND blogId IS NOT NULL", true).
+			Order("id ASC").
+			Offset(postOffset).
+			Limit(postLimit).
+			Find(&posts).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	blogIDs := []uint64{}
+	for _, b := range blogs {
+		blogIDs = append(blogIDs, b.ID)
+	}
+
+	logos, err := findSitemapImages(logoFieldCfg, blogIDs, origin)
+	if err != nil {
+		return nil, err
+	}
+
+	err = loadBlogsAliases(blogs)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"error": err,
+		}).Error("NewBlogSitemap error on load blog aliases")
+	}
+
+	for _, b := range blogs {
+		sitemap.URLs = append(sitemap.URLs, &SitemapURL{
+			Loc:     origin + b.GetPath(),
+			LastMod: formatSitemapDate(b.UpdatedAt),
+			Images:  logos[b.ID],
+		})
+	}
+
+	preloadBlogPostsBlogSlugs(posts)
+
+	postIDs := []uint64{}
+	for _, p := range posts {
+		postIDs = append(postIDs, p.ID)
+	}
+
+	images, err := findSitemapImages(featuredImageFieldCfg, postIDs, origin)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range posts {
+		sitemap.URLs = append(sitemap.URLs, &SitemapURL{
+			Loc:     origin + p.GetPath(),
+			LastMod: formatSitemapDate(p.UpdatedAt),
+			Images:  images[p.ID],
+		})
+	}
+
+	return &sitemap, nil
+}
+
+type sitemapImageRow struct {
+	ModelID uint64 `gorm:"column:modelId"`
+	URLs    []byte `gorm:"column:urls"`
+}
+
+// findSitemapImages - Original image urls in the field by model id, loaded in batches
+func findSitemapImages(cfg files.FieldConfigurationInterface, modelIDs []uint64, origin string) (map[uint64][]*SitemapImage, error) {
+	result := map[uint64][]*SitemapImage{}
+
+	if cfg == nil || len(modelIDs) == 0 {
+		return result, nil
+	}
+
+	db := catu.GetDefaultDatabaseConnection()
+
+	for start := 0; start < len(modelIDs); start += sitemapImagesBatchSize {
+		end := start + sitemapImagesBatchSize
+		if end > len(modelIDs) {
+			end = len(modelIDs)
+		}
+
+		rows := []sitemapImageRow{}
+		err := db.
+			Table("images").
+			Select("imageassocs.modelId, images.urls").
+			Joins("INNER JOIN imageassocs ON imageassocs.imageId = images.id").
+			Where("imageassocs.modelName = ? AND imageassocs.field = ?", cfg.GetModelName(), cfg.GetFieldName()).
+			Where("imageassocs.modelId IN ?", modelIDs[start:end]).
+			Order("imageassocs.modelId ASC").
+			Order("imageassocs.id ASC").
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			var urls files.ImageURL
+			err = json.Unmarshal(row.URLs, &urls)
+			if err != nil || urls["original"] == "" {
+				continue
+			}
+
+			result[row.ModelID] = append(result[row.ModelID], &SitemapImage{Loc: absoluteURL(origin, urls["original"])})
+		}
+	}
+
+	return result, nil
+}
+
+func formatSitemapDate(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+
+	return date.UTC().Format(time.RFC3339)
+}
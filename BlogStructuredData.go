@@ -0,0 +1,291 @@
+package blog
+
+import (
+	"encoding/json"
+	"html/template"
+	"strings"
+	"time"
+
+	"github.com/go-catupiry/catu"
+	"github.com/go-catupiry/files"
+)
+
+// JSONLDBlogPosting schema.org BlogPosting
+type JSONLDBlogPosting struct {
+	Context          string              `json:"@context"`
+	Type             string              `json:"@type"`
+	Headline         string              `json:"headline"`
+	Description      string              `json:"description,omitempty"`
+	URL              string              `json:"url,omitempty"`
+	MainEntityOfPage string              `json:"mainEntityOfPage,omitempty"`
+	DatePublished    string              `json:"datePublished,omitempty"`
+	DateModified     string              `json:"dateModified,omitempty"`
+	Author           []*JSONLDThing      `json:"author,omitempty"`
+	Image            []string            `json:"image,omitempty"`
+	Keywords         string              `json:"keywords,omitempty"`
+	ArticleSection   string              `json:"articleSection,omitempty"`
+	Publisher        *JSONLDOrganization `json:"publisher,omitempty"`
+}
+
+// JSONLDBlog schema.org Blog
+type JSONLDBlog struct {
+	Context     string              `json:"@context"`
+	Type        string              `json:"@type"`
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	URL         string              `json:"url,omitempty"`
+	Image       string              `json:"image,omitempty"`
+	Keywords    string              `json:"keywords,omitempty"`
+	Publisher   *JSONLDOrganization `json:"publisher,omitempty"`
+}
+
+// JSONLDThing schema.org Person or Organization reference
+type JSONLDThing struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+type JSONLDOrganization struct {
+	Type string             `json:"@type"`
+	Name string             `json:"name"`
+	URL  string             `json:"url,omitempty"`
+	Logo *JSONLDImageObject `json:"logo,omitempty"`
+}
+
+type JSONLDImageObject struct {
+	Type string `json:"@type"`
+	URL  string `json:"url"`
+}
+
+// HTMLMetaTag - One extra meta tag, Open Graph tags use Property and Twitter tags use Name
+type HTMLMetaTag struct {
+	Property string
+	Name     string
+	Content  string
+}
+
+// HTML - Render the escaped meta tag
+func (t *HTMLMetaTag) HTML() template.HTML {
+	attr := `property="` + template.HTMLEscapeString(t.Property) + `"`
+	if t.Name != "" {
+		attr = `name="` + template.HTMLEscapeString(t.Name) + `"`
+	}
+
+	return template.HTML(`<meta ` + attr + ` content="` + template.HTMLEscapeString(t.Content) + `">`)
+}
+
+// absoluteURL - Add the app origin in relative urls
+func absoluteURL(origin, u string) string {
+	if strings.HasPrefix(u, "/") && !strings.HasPrefix(u, "//") {
+		return origin + u
+	}
+
+	return u
+}
+
+// getImageURL - Absolute url of the image style, fallback to the original image
+func getImageURL(origin string, image *files.ImageModel, style string) string {
+	u := image.URLs[style]
+	if u == "" {
+		u = image.URLs["original"]
+	}
+
+	return absoluteURL(origin, u)
+}
+
+// NewJSONLDPublisher - Site organization from SITE_NAME and SITE_LOGO_URL configurations
+func NewJSONLDPublisher() *JSONLDOrganization {
+	cfg := catu.GetConfiguration()
+	origin := cfg.Get("APP_ORIGIN")
+
+	publisher := JSONLDOrganization{
+		Type: "Organization",
+		Name: cfg.Get("SITE_NAME"),
+		URL:  origin,
+	}
+
+	if logo := cfg.Get("SITE_LOGO_URL"); logo != "" {
+		publisher.Logo = &JSONLDImageObject{Type: "ImageObject", URL: absoluteURL(origin, logo)}
+	}
+
+	return &publisher
+}
+
+// NewJSONLDBlogPosting - BlogPosting from the post and authors, used after LoadData
+func NewJSONLDBlogPosting(r *BlogPostModel) *JSONLDBlogPosting {
+	origin := catu.GetConfiguration().Get("APP_ORIGIN")
+	publisher := NewJSONLDPublisher()
+
+	d := JSONLDBlogPosting{
+		Context:          "https://schema.org",
+		Type:             "BlogPosting",
+		Headline:         r.Title,
+		Description:      r.Teaser,
+		URL:              r.LinkPermanent,
+		MainEntityOfPage: r.LinkPermanent,
+		DatePublished:    r.GetPublishedDate().Format(time.RFC3339),
+		DateModified:     r.UpdatedAt.Format(time.RFC3339),
+		Keywords:         strings.Join(r.Tags, ", "),
+		Publisher:        publisher,
+	}
+
+	if r.Blog != nil {
+		d.ArticleSection = r.Blog.Title
+	}
+
+	for _, image := range r.FeaturedImage {
+		d.Image = append(d.Image, getImageURL(origin, image, "large"))
+	}
+
+	for _, a := range r.Authors {
+		d.Author = append(d.Author, &JSONLDThing{Type: "Person", Name: a.DisplayName, URL: a.LinkPermanent})
+	}
+
+	if len(d.Author) == 0 {
+		d.Author = []*JSONLDThing{{Type: "Organization", Name: publisher.Name, URL: publisher.URL}}
+	}
+
+	return &d
+}
+
+// NewJSONLDBlog - Blog from the blog record, used after LoadData
+func NewJSONLDBlog(r *BlogModel) *JSONLDBlog {
+	origin := catu.GetConfiguration().Get("APP_ORIGIN")
+
+	d := JSONLDBlog{
+		Context:     "https://schema.org",
+		Type:        "Blog",
+		Name:        r.Title,
+		Description: r.Description,
+		URL:         r.LinkPermanent,
+		Keywords:    strings.Join(r.Tags, ", "),
+		Publisher:   NewJSONLDPublisher(),
+	}
+
+	if r.HasLogo {
+		d.Image = getImageURL(origin, r.Logo[0], "large")
+	}
+
+	return &d
+}
+
+// renderJSONLD - JSON-LD script tag, json.Marshal escapes the html characters
+func renderJSONLD(data interface{}) (template.HTML, error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+
+	return template.HTML(`<script type="application/ld+json">` + string(body) + `</script>`), nil
+}
+
+// GetPublishedDate - Publish date, fallback to the creation date for old posts
+func (r *BlogPostModel) GetPublishedDate() time.Time {
+	if r.PublishedAt != nil {
+		return *r.PublishedAt
+	}
+
+	return r.CreatedAt
+}
+
+// setSocialMetaTags - Set the shared Open Graph and Twitter card meta tags
+func setSocialMetaTags(ctx *catu.RequestContext, ogType, imageURL string) {
+	cfg := catu.GetConfiguration()
+
+	ctx.MetaTags.Type = ogType
+	ctx.MetaTags.ImageURL = imageURL
+	if ctx.MetaTags.SiteName == "" {
+		ctx.MetaTags.SiteName = cfg.Get("SITE_NAME")
+	}
+	if ctx.MetaTags.TwitterSite == "" {
+		ctx.MetaTags.TwitterSite = cfg.Get("SITE_TWITTER")
+	}
+}
+
+// LoadBlogPostPageMetadata - Set the post meta tags, "jsonLD" and the "articleMetaTags" list in the context
+func LoadBlogPostPageMetadata(ctx *catu.RequestContext, r *BlogPostModel) error {
+	origin := catu.GetConfiguration().Get("APP_ORIGIN")
+
+	data := NewJSONLDBlogPosting(r)
+
+	imageURL := ""
+	if r.HasFeaturedImage {
+		imageURL = getImageURL(origin, r.FeaturedImage[0], "medium")
+	}
+
+	ctx.MetaTags.Title = r.Title
+	ctx.MetaTags.Description = r.Teaser
+	ctx.MetaTags.Keywords = data.Keywords
+	setSocialMetaTags(ctx, "article", imageURL)
+
+	twitterCard := "summary"
+	if imageURL != "" {
+		twitterCard = "summary_large_image"
+	}
+
+	metaTags := []*HTMLMetaTag{
+		{Property: "article:published_time", Content: data.DatePublished},
+		{Property: "article:modified_time", Content: data.DateModified},
+	}
+	if data.ArticleSection != "" {
+		metaTags = append(metaTags, &HTMLMetaTag{Property: "article:section", Content: data.ArticleSection})
+	}
+	for _, a := range data.Author {
+		if a.Type == "Person" {
+			metaTags = append(metaTags, &HTMLMetaTag{Property: "article:author", Content: a.URL})
+		}
+	}
+	for _, tag := range r.Tags {
+		metaTags = append(metaTags, &HTMLMetaTag{Property: "article:tag", Content: tag})
+	}
+	metaTags = append(metaTags,
+		&HTMLMetaTag{Name: "twitter:card", Content: twitterCard},
+		&HTMLMetaTag{Name: "twitter:title", Content: r.Title},
+		&HTMLMetaTag{Name: "twitter:description", Content: r.Teaser},
+	)
+	if imageURL != "" {
+		metaTags = append(metaTags, &HTMLMetaTag{Name: "twitter:image", Content: imageURL})
+	}
+
+	ctx.Set("articleMetaTags", metaTags)
+
+	jsonLD, err := renderJSONLD(data)
+	if err != nil {
+		return err
+	}
+	ctx.Set("jsonLD", jsonLD)
+
+	return nil
+}
+
+// LoadBlogPageMetadata - Set the blog meta tags and "jsonLD" in the context
+func LoadBlogPageMetadata(ctx *catu.RequestContext, r *BlogModel) error {
+	origin := catu.GetConfiguration().Get("APP_ORIGIN")
+
+	data := NewJSONLDBlog(r)
+
+	imageURL := ""
+	if r.HasLogo {
+		imageURL = getImageURL(origin, r.Logo[0], "medium")
+	}
+
+	ctx.MetaTags.Title = r.Title
+	ctx.MetaTags.Description = r.Description
+	ctx.MetaTags.Keywords = data.Keywords
+	setSocialMetaTags(ctx, "website", imageURL)
+
+	ctx.Set("articleMetaTags", []*HTMLMetaTag{
+		{Name: "twitter:card", Content: "summary"},
+		{Name: "twitter:title", Content: r.Title},
+		{Name: "twitter:description", Content: r.Description},
+	})
+
+	jsonLD, err := renderJSONLD(data)
+	if err != nil {
+		return err
+	}
+	ctx.Set("jsonLD", jsonLD)
+
+	return nil
+}
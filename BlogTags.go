@@ -0,0 +1,153 @@
+package blog
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/go-catupiry/catu"
+	"github.com/go-catupiry/tags"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var blogPostTagsFieldCfg tags.FieldConfigurationInterface
+
+const blogTagsVocabulary = "Tags"
+
+// tags in the blog page block
+const blogTagCloudBlockLimit = 30
+
+// normalizeTags - Lowercase, trim and remove empty and duplicated tags keeping the order
+func normalizeTags(list []string) []string {
+	result := []string{}
+	seen := map[string]bool{}
+
+	for _, t := range list {
+		t = normalizeTag(t)
+		if t == "" || seen[t] {
+			continue
+		}
+
+		seen[t] = true
+		result = append(result, t)
+	}
+
+	return result
+}
+
+func normalizeTag(t string) string {
+	return strings.ToLower(strings.Join(strings.Fields(t), " "))
+}
+
+// RefreshTerms - Load the post tags
+func (r *BlogPostModel) RefreshTerms() error {
+	records := []tags.TermModel{}
+	err := blogPostTagsFieldCfg.FindManyTerm(r.GetIDString(), &records)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		logrus.WithFields(logrus.Fields{
+			"id":    r.ID,
+			"error": err,
+		}).Error("BlogPost.RefreshTerms error on find tags")
+		return err
+	}
+
+	r.TagsRecords = records
+	r.Tags = []string{}
+	for i := range records {
+		r.Tags = append(r.Tags, records[i].Text)
+	}
+
+	return nil
+}
+
+// saveTags - Replace the record tags, nil tags are not changed
+func saveTags(cfg tags.FieldConfigurationInterface, modelID string, list []string) error {
+	if list == nil || cfg == nil {
+		return nil
+	}
+
+	return cfg.Update(modelID, normalizeTags(list))
+}
+
+// clearTags - Remove all tags from the record
+func clearTags(cfg tags.FieldConfigurationInterface, modelID string) error {
+	if cfg == nil {
+		return nil
+	}
+
+	return cfg.Update(modelID, []string{})
+}
+
+// blogPostTagSubQuery - Ids of the blog posts with the tag, used in "id IN (?)" filters
+func blogPostTagSubQuery(db *gorm.DB, tag string) *gorm.DB {
+	return db.
+		Table("modelsterms").
+		Select("modelsterms.modelId").
+		Joins("INNER JOIN terms ON terms.id = modelsterms.termId").
+		Where("modelsterms.modelName = ? AND modelsterms.field = ? AND modelsterms.vocabularyName = ?", "blog-post", "tags", blogTagsVocabulary).
+		Where("terms.text = ?", normalizeTag(tag))
+}
+
+// BlogTagCount - Tag with the number of published posts using it
+type BlogTagCount struct {
+	Text          string `json:"text"`
+	Count         int64  `json:"count"`
+	LinkPermanent string `json:"linkPermanent"`
+}
+
+// GetPath - Tag page path
+func (r *BlogTagCount) GetPath() string {
+	return "/blogs/tags/" + url.PathEscape(r.Text)
+}
+
+// BlogTagCloud - Find the most used tags in the published posts, use blogID nil for all blogs
+func BlogTagCloud(blogID *uint64, limit int, records *[]*BlogTagCount) error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	query := db.
+		Table("modelsterms").
+		Select("terms.text AS text, COUNT(DISTINCT modelsterms.modelId) AS count").
+		Joins("INNER JOIN terms ON terms.id = modelsterms.termId").
+		Joins("INNER JOIN blog_posts ON blog_posts.id = modelsterms.modelId").
+		Where("modelsterms.modelName = ? AND modelsterms.field = ? AND modelsterms.vocabularyName = ?", "blog-post", "tags", blogTagsVocabulary).
+		Where("blog_posts.published = ?", true)
+
+	if blogID != nil {
+		query = query.Where("blog_posts.blogId = ?", *blogID)
+	}
+
+	err := query.
+		Group("terms.text").
+		Order("count DESC").
+		Order("text ASC").
+		Limit(limit).
+		Scan(records).Error
+	if err != nil {
+		return err
+	}
+
+	origin := catu.GetConfiguration().Get("APP_ORIGIN")
+	for _, r := range *records {
+		r.LinkPermanent = origin + r.GetPath()
+	}
+
+	return nil
+}
+
+// LoadTagCloudBlockData - Set the blog tag cloud in the "tagCloud" context variable
+func LoadTagCloudBlockData(ctx *catu.RequestContext, blogID *uint64, limit int) error {
+	records := []*BlogTagCount{}
+	err := BlogTagCloud(blogID, limit, &records)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"err": err,
+		}).Error("LoadTagCloudBlockData error on find tags")
+		return err
+	}
+
+	ctx.Set("hasTagCloud", len(records) > 0)
+	ctx.Set("tagCloud", records)
+
+	return nil
+}
@@ -0,0 +1,297 @@
+package blog
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-catupiry/catu"
+	"github.com/go-catupiry/catu/helpers"
+	"github.com/go-catupiry/drouter"
+	"github.com/gosimple/slug"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const slugMaxLength = 60
+
+// makeSlug - URL slug from the text. Numeric slugs are prefixed to not conflict with ids
+func makeSlug(text, prefix string) string {
+	s := slug.Make(text)
+	s = strings.Trim(helpers.TruncateString(s, slugMaxLength, ""), "-")
+
+	if s == "" {
+		return prefix
+	}
+
+	if _, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return prefix + "-" + s
+	}
+
+	return s
+}
+
+// uniqueSlug - Add a numeric suffix in the slug until the exists check returns false
+func uniqueSlug(base string, exists func(s string) (bool, error)) (string, error) {
+	s := base
+
+	for i := 2; i < 100; i++ {
+		found, err := exists(s)
+		if err != nil {
+			return "", err
+		}
+
+		if !found {
+			return s, nil
+		}
+
+		s = base + "-" + strconv.Itoa(i)
+	}
+
+	return base + "-" + strconv.FormatInt(time.Now().UnixNano(), 36), nil
+}
+
+// blogReservedSlugs - Blog slugs used by other /blogs/ routes
+var blogReservedSlugs = []string{"tags", "authors"}
+
+// blogSlugExists - Check if other blog already uses the slug or if it is reserved
+func blogSlugExists(s string, exceptID uint64) (bool, error) {
+	for _, reserved := range blogReservedSlugs {
+		if s == reserved {
+			return true, nil
+		}
+	}
+
+	db := catu.GetDefaultDatabaseConnection()
+
+	var count int64
+	err := db.Model(&BlogModel{}).
+		Where("urlUniquePath = ? AND id <> ?", s, exceptID).
+		Count(&count).Error
+
+	return count > 0, err
+}
+
+// blogPostSlugExists - Check if other post in the same blog already uses the slug
+func blogPostSlugExists(s string, blogID *uint64, exceptID uint64) (bool, error) {
+	db := catu.GetDefaultDatabaseConnection()
+
+	query := db.Model(&BlogPostModel{}).
+		Where("urlPath = ? AND id <> ?", s, exceptID)
+
+	if blogID == nil {
+		query = query.Where("blogId IS NULL")
+	} else {
+		query = query.Where("blogId = ?", *blogID)
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+
+	return count > 0, err
+}
+
+// isURLAliasEnabled - Url aliases are only resolved by drouter with the URL_ALIAS_ENABLE configuration
+func isURLAliasEnabled() bool {
+	return catu.GetConfiguration().Get("URL_ALIAS_ENABLE") != ""
+}
+
+// GetPath - Canonical blog path, the url alias if loaded and enabled or /blogs/<slug|id>
+func (r *BlogModel) GetPath() string {
+	if r.Alias != nil && r.Alias.Alias != "" && isURLAliasEnabled() {
+		return r.Alias.Alias
+	}
+
+	if r.URLUniquePath != "" {
+		return "/blogs/" + r.URLUniquePath
+	}
+
+	if r.ID != 0 {
+		return r.GetTargetPath()
+	}
+
+	return ""
+}
+
+// LoadAlias - Load the blog url alias, used in GetPath for custom aliases
+func (r *BlogModel) LoadAlias() error {
+	if r.ID == 0 || !isURLAliasEnabled() {
+		return nil
+	}
+
+	var alias drouter.UrlAliasModel
+	err := drouter.URLAliasFindOneByTarget(r.GetTargetPath(), &alias)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	r.Alias = &alias
+
+	return nil
+}
+
+// max targets in one url aliases query
+const blogAliasesBatchSize = 1000
+
+// loadBlogsAliases - Load the url aliases of a blog list with one query per batch
+func loadBlogsAliases(blogs []*BlogModel) error {
+	if len(blogs) == 0 || !isURLAliasEnabled() {
+		return nil
+	}
+
+	db := catu.GetDefaultDatabaseConnection()
+
+	for start := 0; start < len(blogs); start += blogAliasesBatchSize {
+		end := start + blogAliasesBatchSize
+		if end > len(blogs) {
+			end = len(blogs)
+		}
+
+		targets := []string{}
+		for _, b := range blogs[start:end] {
+			targets = append(targets, b.GetTargetPath())
+		}
+
+		aliases := []*drouter.UrlAliasModel{}
+		err := db.
+			Where("target IN ?", targets).
+			Order("id ASC").
+			Find(&aliases).Error
+		if err != nil {
+			return err
+		}
+
+		// same as URLAliasFindOneByTarget, the first alias of each target
+		byTarget := map[string]*drouter.UrlAliasModel{}
+		for _, a := range aliases {
+			if _, ok := byTarget[a.Target]; !ok {
+				byTarget[a.Target] = a
+			}
+		}
+
+		for _, b := range blogs[start:end] {
+			if a, ok := byTarget[b.GetTargetPath()]; ok {
+				b.Alias = a
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetPath - Canonical blog post path, /blogs/<blog-slug|id>/<post-slug|id>. Posts without blog don't have one page
+func (r *BlogPostModel) GetPath() string {
+	if r.ID == 0 || r.BlogID == nil {
+		return ""
+	}
+
+	postSegment := r.URLPath
+	if postSegment == "" {
+		postSegment = r.GetIDString()
+	}
+
+	return "/blogs/" + r.getBlogSlug() + "/" + postSegment
+}
+
+// getBlogSlug - Blog slug from the loaded blog or from database, fallback to the blog id
+func (r *BlogPostModel) getBlogSlug() string {
+	if r.Blog != nil && r.Blog.ID == *r.BlogID && r.Blog.URLUniquePath != "" {
+		return r.Blog.URLUniquePath
+	}
+
+	if r.blogSlug == "" || r.blogSlugID != *r.BlogID {
+		r.blogSlugID = *r.BlogID
+
+		var blog BlogModel
+		db := catu.GetDefaultDatabaseConnection()
+		db.Omit("Editors").Select("id", "urlUniquePath").Where("id = ?", *r.BlogID).Find(&blog)
+
+		r.blogSlug = blog.URLUniquePath
+		if r.blogSlug == "" {
+			r.blogSlug = strconv.FormatUint(*r.BlogID, 10)
+		}
+	}
+
+	return r.blogSlug
+}
+
+// preloadBlogPostsBlogSlugs - Load the blog slugs of a post list with one query, used in the post GetPath.
+// Errors are only logged, GetPath loads the missing slugs one by one
+func preloadBlogPostsBlogSlugs(posts []*BlogPostModel) {
+	seen := map[uint64]bool{}
+	ids := []uint64{}
+	for _, p := range posts {
+		if p.BlogID != nil && !seen[*p.BlogID] {
+			seen[*p.BlogID] = true
+			ids = append(ids, *p.BlogID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return
+	}
+
+	db := catu.GetDefaultDatabaseConnection()
+
+	blogs := []*BlogModel{}
+	err := db.
+		Omit("Editors").
+		Select("id", "urlUniquePath").
+		Where("id IN ?", ids).
+		Find(&blogs).Error
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"error": err,
+		}).Error("preloadBlogPostsBlogSlugs error on find blogs")
+		return
+	}
+
+	slugs := map[uint64]string{}
+	for _, b := range blogs {
+		slugs[b.ID] = b.URLUniquePath
+	}
+
+	for _, p := range posts {
+		if p.BlogID == nil {
+			continue
+		}
+
+		p.blogSlugID = *p.BlogID
+		p.blogSlug = slugs[*p.BlogID]
+		if p.blogSlug == "" {
+			p.blogSlug = strconv.FormatUint(*p.BlogID, 10)
+		}
+	}
+}
+
+// setCanonicalURL - Set the canonical url in the page meta tags and redirect other paths to it with 301.
+// Returns true if the response was sent
+func setCanonicalURL(ctx *catu.RequestContext, path string) (bool, error) {
+	if path == "" {
+		return false, nil
+	}
+
+	requestPath, _ := ctx.Get("pathBeforeAlias").(string)
+	if requestPath == "" {
+		requestPath = ctx.Request().URL.Path
+	}
+
+	if requestPath != path {
+		location := path
+		if ctx.QueryString() != "" {
+			location += "?" + ctx.QueryString()
+		}
+
+		return true, ctx.Redirect(http.StatusMovedPermanently, location)
+	}
+
+	ctx.PathBeforeAlias = path
+	ctx.MetaTags.Canonical = ctx.AppOrigin + path
+
+	return false, nil
+}
@@ -0,0 +1,190 @@
+package blog
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-catupiry/catu"
+	"github.com/go-catupiry/drouter"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// BlogURLRedirectModel Stores old blog and blog post aliases, requests to the old path are redirected with 301
+type BlogURLRedirectModel struct {
+	ID        uint64    `gorm:"primaryKey;column:id" json:"id"`
+	Path      string    `gorm:"uniqueIndex:blogURLRedirectPath;column:path;type:varchar(255);not null" json:"path"`
+	Target    string    `gorm:"index:blogURLRedirectTarget;column:target;type:varchar(255);not null" json:"target"`
+	CreatedAt time.Time `gorm:"column:createdAt;type:datetime;not null" json:"createdAt"`
+}
+
+// TableName get sql table name
+func (m *BlogURLRedirectModel) TableName() string {
+	return "blog_url_redirects"
+}
+
+// BlogURLRedirectUpsert - Redirect the path to the target, updating the target if the path already exists
+func BlogURLRedirectUpsert(path, target string) error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	var record BlogURLRedirectModel
+	err := db.Where("path = ?", path).Find(&record).Error
+	if err != nil {
+		return err
+	}
+
+	if record.ID != 0 {
+		if record.Target == target {
+			return nil
+		}
+
+		return db.Model(&record).Update("target", target).Error
+	}
+
+	record.Path = path
+	record.Target = target
+
+	return db.Create(&record).Error
+}
+
+func BlogURLRedirectFindByPath(path string, record *BlogURLRedirectModel) error {
+	db := catu.GetDefaultDatabaseConnection()
+
+	return db.
+		Where("path = ?", path).
+		First(record).Error
+}
+
+func BlogURLRedirectDeleteByPath(path string) error {
+	db := catu.GetDefaultDatabaseConnection()
+	return db.Where("path = ?", path).Delete(&BlogURLRedirectModel{}).Error
+}
+
+func BlogURLRedirectDeleteByTarget(target string) error {
+	db := catu.GetDefaultDatabaseConnection()
+	return db.Where("target = ?", target).Delete(&BlogURLRedirectModel{}).Error
+}
+
+// upsertBlogURLAlias - Set the url alias for the target and keep the old alias as one redirect
+func upsertBlogURLAlias(alias, target string) (*drouter.UrlAliasModel, error) {
+	var old drouter.UrlAliasModel
+	err := drouter.URLAliasFindOneByTarget(target, &old)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var record drouter.UrlAliasModel
+	err = drouter.URLAliasUpsert(alias, target, "", &record)
+	if err != nil {
+		return nil, err
+	}
+
+	if old.Alias != "" && old.Alias != alias {
+		err = BlogURLRedirectUpsert(old.Alias, target)
+		if err != nil {
+			return &record, err
+		}
+	}
+
+	// the new alias can be one old path from this or other record:
+	err = BlogURLRedirectDeleteByPath(alias)
+	if err != nil {
+		return &record, err
+	}
+
+	return &record, nil
+}
+
+// deleteBlogURLAlias - Delete the alias and the redirects of one target path
+func deleteBlogURLAlias(target string) error {
+	err := drouter.URLAliasDeleteByTarget(target)
+	if err != nil {
+		return err
+	}
+
+	return BlogURLRedirectDeleteByTarget(target)
+}
+
+// moveBlogURLAlias - Change the target of the alias and redirects, the old alias is kept as one redirect.
+// If the new target is empty the alias and redirects are deleted
+func moveBlogURLAlias(oldTarget, newTarget string) error {
+	if newTarget == "" {
+		return deleteBlogURLAlias(oldTarget)
+	}
+
+	var old drouter.UrlAliasModel
+	err := drouter.URLAliasFindOneByTarget(oldTarget, &old)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	if old.Alias != "" {
+		err = BlogURLRedirectUpsert(old.Alias, newTarget)
+		if err != nil {
+			return err
+		}
+	}
+
+	db := catu.GetDefaultDatabaseConnection()
+	err = db.Model(&BlogURLRedirectModel{}).
+		Where("target = ?", oldTarget).
+		Update("target", newTarget).Error
+	if err != nil {
+		return err
+	}
+
+	return drouter.URLAliasDeleteByTarget(oldTarget)
+}
+
+// redirectFromOldURL - Redirect with 301 if the request path is one old blog or blog post alias.
+// Returns true if the response was sent
+func redirectFromOldURL(c echo.Context) (bool, error) {
+	path, _ := c.Get("pathBeforeAlias").(string)
+	if path == "" {
+		path = c.Request().URL.Path
+	}
+
+	var record BlogURLRedirectModel
+	err := BlogURLRedirectFindByPath(path, &record)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	location := record.Target
+
+	var alias drouter.UrlAliasModel
+	err = drouter.URLAliasFindOneByTarget(record.Target, &alias)
+	if err == nil && alias.Alias != "" {
+		location = alias.Alias
+	}
+
+	if c.QueryString() != "" {
+		location += "?" + c.QueryString()
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"path":     path,
+		"location": location,
+	}).Debug("redirectFromOldURL redirecting old url")
+
+	return true, c.Redirect(http.StatusMovedPermanently, location)
+}
+
+// isNotFoundError - Check if the error is one record not found or one 404 HTTP error
+func isNotFoundError(err error) bool {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true
+	}
+
+	var httpErr *catu.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.Code == http.StatusNotFound
+	}
+
+	return false
+}
@@ -31,6 +31,8 @@ func LoadHomeBlogPostBlockData(ctx *catu.RequestContext) error {
 		"len_records_found": len(latestPosts),
 	}).Debug("BlogPostFindAll count result")
 
+	preloadBlogPostsBlogSlugs(latestPosts)
+
 	for i := range latestPosts {
 		latestPosts[i].LoadTeaserData()
 
@@ -0,0 +1,583 @@
+package blog
+
+import (
+	"html"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/go-catupiry/catu"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// max number of ids ordered by relevance, other results are ordered after them with the default order
+const localSearchMaxRanked = 1000
+
+// max number of ids in the search filter, less relevant results are skipped to keep the SQL query small
+const localSearchMaxFiltered = 10000
+
+// max number of cached queries in each index, the cache is cleared on every change
+const localSearchCacheSize = 100
+
+// title terms count more than body terms in the score
+const localSearchTitleWeight = 3
+
+var blogPostLocalIndex = NewLocalSearchIndex()
+var blogLocalIndex = NewLocalSearchIndex()
+
+// only one rebuild can run at a time
+var localSearchRebuildMu sync.Mutex
+
+// LocalSearchIndex - In memory inverted index with accent insensitive and stemmed pt-BR terms
+type LocalSearchIndex struct {
+	mu sync.RWMutex
+	// term -> document id -> term frequency
+	postings map[string]map[uint64]int
+	// document id -> terms, used to remove the old terms
+	docs map[uint64][]string
+	// changes made while one rebuild is running, replayed in the new index
+	recording bool
+	journal   []localSearchChange
+
+	cacheMu sync.Mutex
+	// query -> ordered ids, the filter, count and order of one request use the same search
+	cache map[string][]uint64
+	// incremented on every cache clear, searches started before it are not cached
+	generation uint64
+}
+
+// localSearchChange - One Set or Remove call, freq is nil for removes
+type localSearchChange struct {
+	id   uint64
+	freq map[string]int
+}
+
+func NewLocalSearchIndex() *LocalSearchIndex {
+	return &LocalSearchIndex{
+		postings: map[string]map[uint64]int{},
+		docs:     map[uint64][]string{},
+		cache:    map[string][]uint64{},
+	}
+}
+
+// Set - Add or replace one document in the index
+func (idx *LocalSearchIndex) Set(id uint64, title string, texts ...string) {
+	freq := map[string]int{}
+
+	for _, term := range TokenizeSearchText(title) {
+		freq[term] += localSearchTitleWeight
+	}
+
+	for _, text := range texts {
+		for _, term := range TokenizeSearchText(text) {
+			freq[term]++
+		}
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.set(id, freq)
+
+	if idx.recording {
+		idx.journal = append(idx.journal, localSearchChange{id: id, freq: freq})
+	}
+
+	idx.clearCache()
+}
+
+func (idx *LocalSearchIndex) set(id uint64, freq map[string]int) {
+	idx.remove(id)
+
+	terms := make([]string, 0, len(freq))
+	for term, count := range freq {
+		if idx.postings[term] == nil {
+			idx.postings[term] = map[uint64]int{}
+		}
+		idx.postings[term][id] = count
+		terms = append(terms, term)
+	}
+
+	idx.docs[id] = terms
+}
+
+// Remove - Remove one document from the index
+func (idx *LocalSearchIndex) Remove(id uint64) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.remove(id)
+
+	if idx.recording {
+		idx.journal = append(idx.journal, localSearchChange{id: id})
+	}
+
+	idx.clearCache()
+}
+
+func (idx *LocalSearchIndex) remove(id uint64) {
+	for _, term := range idx.docs[id] {
+		delete(idx.postings[term], id)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+
+	delete(idx.docs, id)
+}
+
+func (idx *LocalSearchIndex) clearCache() {
+	idx.cacheMu.Lock()
+	defer idx.cacheMu.Unlock()
+
+	idx.cache = map[string][]uint64{}
+	idx.generation++
+}
+
+// StartRecording - Record the next changes to replay them in the index passed to Replace, used in rebuilds
+func (idx *LocalSearchIndex) StartRecording() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.recording = true
+	idx.journal = nil
+}
+
+// StopRecording - Stop and discard the recorded changes, used if the rebuild fails
+func (idx *LocalSearchIndex) StopRecording() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.recording = false
+	idx.journal = nil
+}
+
+// Replace - Replace the index content with the content of other index, used in rebuilds.
+// The changes recorded after StartRecording are applied in the new content before the swap
+func (idx *LocalSearchIndex) Replace(other *LocalSearchIndex) {
+	other.mu.Lock()
+	defer other.mu.Unlock()
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	for _, change := range idx.journal {
+		if change.freq == nil {
+			other.remove(change.id)
+		} else {
+			other.set(change.id, change.freq)
+		}
+	}
+
+	idx.postings = other.postings
+	idx.docs = other.docs
+	idx.recording = false
+	idx.journal = nil
+
+	idx.clearCache()
+}
+
+// Len - Number of documents in the index
+func (idx *LocalSearchIndex) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return len(idx.docs)
+}
+
+// Search - Find the ids of documents with all query terms, ordered by tf-idf score. Use limit 0 to get all ids
+func (idx *LocalSearchIndex) Search(q string, limit int) []uint64 {
+	key := strings.Join(TokenizeSearchText(q), " ")
+
+	ids, generation, ok := idx.cached(key)
+	if !ok {
+		ids = idx.search(strings.Fields(key))
+		idx.storeCache(key, ids, generation)
+	}
+
+	if limit > 0 && len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	return ids
+}
+
+// cached - Get the cached search result and the cache generation, used in storeCache
+func (idx *LocalSearchIndex) cached(key string) ([]uint64, uint64, bool) {
+	idx.cacheMu.Lock()
+	defer idx.cacheMu.Unlock()
+
+	ids, ok := idx.cache[key]
+
+	return ids, idx.generation, ok
+}
+
+// storeCache - Cache the search result if the index didn't change after the generation was read
+func (idx *LocalSearchIndex) storeCache(key string, ids []uint64, generation uint64) {
+	idx.cacheMu.Lock()
+	defer idx.cacheMu.Unlock()
+
+	if idx.generation != generation {
+		return
+	}
+
+	if len(idx.cache) >= localSearchCacheSize {
+		idx.cache = map[string][]uint64{}
+	}
+	idx.cache[key] = ids
+}
+
+func (idx *LocalSearchIndex) search(terms []string) []uint64 {
+	if len(terms) == 0 {
+		return []uint64{}
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	total := float64(len(idx.docs))
+	scores := map[uint64]float64{}
+
+	for i, term := range terms {
+		postings := idx.postings[term]
+		if len(postings) == 0 {
+			return []uint64{}
+		}
+
+		idf := math.Log(1 + total/float64(len(postings)))
+
+		if i == 0 {
+			for id, count := range postings {
+				scores[id] = float64(count) * idf
+			}
+			continue
+		}
+
+		for id := range scores {
+			count, ok := postings[id]
+			if !ok {
+				delete(scores, id)
+				continue
+			}
+			scores[id] += float64(count) * idf
+		}
+	}
+
+	ids := make([]uint64, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		if scores[ids[i]] == scores[ids[j]] {
+			return ids[i] > ids[j]
+		}
+		return scores[ids[i]] > scores[ids[j]]
+	})
+
+	return ids
+}
+
+var searchStopWords = map[string]bool{
+	"a": true, "o": true, "as": true, "os": true, "e": true, "de": true, "da": true, "do": true,
+	"das": true, "dos": true, "em": true, "no": true, "na": true, "nos": true, "nas": true,
+	"um": true, "uma": true, "uns": true, "umas": true, "ao": true, "aos": true, "para": true,
+	"por": true, "com": true, "que": true, "se": true, "ou": true, "mais": true, "mas": true,
+	"pelo": true, "pela": true, "pelos": true, "pelas": true, "sem": true, "sua": true, "seu": true,
+}
+
+// RemoveAccents - Remove the diacritics from text, "ação" becomes "acao"
+func RemoveAccents(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+	result, _, err := transform.String(t, s)
+	if err != nil {
+		return s
+	}
+
+	return result
+}
+
+// TokenizeSearchText - Split the text in lower case, accent free and stemmed terms without stop words. HTML tags are removed
+func TokenizeSearchText(text string) []string {
+	text = html.UnescapeString(searchSnippetPolicy.Sanitize(text))
+	text = strings.ToLower(RemoveAccents(text))
+
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		if len(w) < 2 || searchStopWords[w] {
+			continue
+		}
+
+		terms = append(terms, StemPortuguese(w))
+	}
+
+	return terms
+}
+
+// StemPortuguese - Light pt-BR stemmer based in the RSLP plural, adverb, augmentative and gender reduction steps.
+// Expects lower case words without accents
+func StemPortuguese(w string) string {
+	if len(w) <= 3 {
+		return w
+	}
+
+	// plural reduction
+	switch {
+	case strings.HasSuffix(w, "coes"):
+		w = w[:len(w)-4] + "cao"
+	case strings.HasSuffix(w, "oes"), strings.HasSuffix(w, "aes"):
+		w = w[:len(w)-3] + "ao"
+	case strings.HasSuffix(w, "ais"):
+		w = w[:len(w)-3] + "al"
+	case strings.HasSuffix(w, "eis"):
+		w = w[:len(w)-3] + "el"
+	case strings.HasSuffix(w, "ois"):
+		w = w[:len(w)-3] + "ol"
+	case strings.HasSuffix(w, "res"), strings.HasSuffix(w, "zes"), strings.HasSuffix(w, "ses"):
+		w = w[:len(w)-2]
+	case strings.HasSuffix(w, "ns"):
+		w = w[:len(w)-2] + "m"
+	case strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss"):
+		w = w[:len(w)-1]
+	}
+
+	// adverb reduction
+	if len(w) > 8 && strings.HasSuffix(w, "mente") {
+		w = w[:len(w)-5]
+	}
+
+	// augmentative and diminutive reduction
+	for _, suffix := range []string{"zinho", "zinha", "inho", "inha", "issimo", "issima"} {
+		if len(w) >= len(suffix)+3 && strings.HasSuffix(w, suffix) {
+			w = w[:len(w)-len(suffix)]
+			break
+		}
+	}
+
+	// gender and final vowel reduction
+	if len(w) > 4 {
+		switch w[len(w)-1] {
+		case 'a', 'o', 'e':
+			w = w[:len(w)-1]
+		}
+	}
+
+	return w
+}
+
+// LocalSearchBackend - Search with the in memory index, use for databases without full text search.
+// The index is loaded on bootstrap and can be rebuilt with RebuildLocalSearchIndex
+type LocalSearchBackend struct{}
+
+func init() {
+	RegisterBlogPostSearchBackend(&LocalSearchBackend{})
+}
+
+func (b *LocalSearchBackend) Name() string {
+	return "local"
+}
+
+func (b *LocalSearchBackend) Setup(db *gorm.DB) error {
+	return nil
+}
+
+func (b *LocalSearchBackend) Available(db *gorm.DB) bool {
+	return true
+}
+
+func (b *LocalSearchBackend) Index(record *BlogPostModel) error {
+	blogPostLocalIndex.Set(record.ID, record.Title, record.Teaser, record.Body, record.SearchTags)
+	return nil
+}
+
+func (b *LocalSearchBackend) Remove(id uint64) error {
+	blogPostLocalIndex.Remove(id)
+	return nil
+}
+
+// Filter - Filter with all matched ids, the published and blog filters run in the SQL query
+func (b *LocalSearchBackend) Filter(query *gorm.DB, q string) *gorm.DB {
+	return localSearchFilter(query, "blog_posts.id", blogPostLocalIndex.Search(q, 0))
+}
+
+func (b *LocalSearchBackend) Relevance(q string) *clause.Expr {
+	return localSearchOrder("blog_posts.id", blogPostLocalIndex.Search(q, localSearchMaxRanked))
+}
+
+// localSearchFilter - IN condition with the ids in the SQL, long lists don't fit in the database placeholders limit.
+// Ids are ordered by relevance and only the first localSearchMaxFiltered are used
+func localSearchFilter(query *gorm.DB, column string, ids []uint64) *gorm.DB {
+	if len(ids) == 0 {
+		return query.Where("1 = 0")
+	}
+
+	if len(ids) > localSearchMaxFiltered {
+		ids = ids[:localSearchMaxFiltered]
+	}
+
+	var sql strings.Builder
+	sql.WriteString(column + " IN (")
+	for i, id := range ids {
+		if i > 0 {
+			sql.WriteString(",")
+		}
+		sql.WriteString(strconv.FormatUint(id, 10))
+	}
+	sql.WriteString(")")
+
+	return query.Where(sql.String())
+}
+
+// localSearchOrder - CASE expression to keep the index order in the SQL query
+func localSearchOrder(column string, ids []uint64) *clause.Expr {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var sql strings.Builder
+	sql.WriteString("CASE " + column)
+	for i, id := range ids {
+		sql.WriteString(" WHEN " + strconv.FormatUint(id, 10) + " THEN " + strconv.Itoa(i))
+	}
+	sql.WriteString(" ELSE " + strconv.Itoa(len(ids)) + " END ASC")
+
+	return &clause.Expr{SQL: sql.String()}
+}
+
+func isLocalSearchEnabled() bool {
+	_, ok := GetBlogPostSearchBackend().(*LocalSearchBackend)
+	return ok
+}
+
+// indexBlog - Update the blog in the local index if it is enabled
+func indexBlog(record *BlogModel) {
+	if !isLocalSearchEnabled() {
+		return
+	}
+
+	blogLocalIndex.Set(record.ID, record.Title, record.DescriptionSmall, record.Description, strings.Join(record.Tags, " "))
+}
+
+// removeBlogFromIndex - Remove the blog from the local index if it is enabled
+func removeBlogFromIndex(id uint64) {
+	if !isLocalSearchEnabled() {
+		return
+	}
+
+	blogLocalIndex.Remove(id)
+}
+
+// setBlogSearchQuery - Set the blog search filter, with the local index if it is enabled or with LIKE
+func setBlogSearchQuery(query *gorm.DB, q string, order bool) *gorm.DB {
+	if !isLocalSearchEnabled() {
+		like := "%" + q + "%"
+		return query.Where("(title LIKE ? OR description LIKE ?)", like, like)
+	}
+
+	query = localSearchFilter(query, "blogs.id", blogLocalIndex.Search(q, 0))
+
+	if order {
+		relevance := localSearchOrder("blogs.id", blogLocalIndex.Search(q, localSearchMaxRanked))
+		if relevance != nil {
+			query = query.Clauses(clause.OrderBy{
+				Expression: clause.Expr{SQL: relevance.SQL + ", createdAt DESC, id DESC"},
+			})
+		}
+	}
+
+	return query
+}
+
+// RebuildLocalSearchIndex - Load all blogs and blog posts from database in new local indexes and replace the current ones
+func RebuildLocalSearchIndex() error {
+	localSearchRebuildMu.Lock()
+	defer localSearchRebuildMu.Unlock()
+
+	db := catu.GetDefaultDatabaseConnection()
+	batchSize := 500
+
+	// saves made while the database is read are replayed in the new indexes
+	blogPostLocalIndex.StartRecording()
+	blogLocalIndex.StartRecording()
+
+	done := false
+	defer func() {
+		if !done {
+			blogPostLocalIndex.StopRecording()
+			blogLocalIndex.StopRecording()
+		}
+	}()
+
+	posts := NewLocalSearchIndex()
+	var lastID uint64
+	for {
+		records := []*BlogPostModel{}
+		err := db.
+			Where("id > ?", lastID).
+			Order("id ASC").
+			Limit(batchSize).
+			Find(&records).Error
+		if err != nil {
+			return errors.Wrap(err, "RebuildLocalSearchIndex error on find blog posts")
+		}
+
+		for _, r := range records {
+			posts.Set(r.ID, r.Title, r.Teaser, r.Body, r.SearchTags)
+			lastID = r.ID
+		}
+
+		if len(records) < batchSize {
+			break
+		}
+	}
+
+	blogs := NewLocalSearchIndex()
+	lastID = 0
+	for {
+		records := []*BlogModel{}
+		err := db.
+			Omit("Editors").
+			Where("id > ?", lastID).
+			Order("id ASC").
+			Limit(batchSize).
+			Find(&records).Error
+		if err != nil {
+			return errors.Wrap(err, "RebuildLocalSearchIndex error on find blogs")
+		}
+
+		for _, r := range records {
+			r.RefreshTerms()
+			blogs.Set(r.ID, r.Title, r.DescriptionSmall, r.Description, strings.Join(r.Tags, " "))
+			lastID = r.ID
+		}
+
+		if len(records) < batchSize {
+			break
+		}
+	}
+
+	blogPostLocalIndex.Replace(posts)
+	blogLocalIndex.Replace(blogs)
+	done = true
+
+	logrus.WithFields(logrus.Fields{
+		"blogPosts": posts.Len(),
+		"blogs":     blogs.Len(),
+	}).Info("RebuildLocalSearchIndex done")
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package blog
+
+import (
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestLocalSearchIndexSkipStaleCache(t *testing.T) {
+	idx := NewLocalSearchIndex()
+	idx.Set(1, "alpha")
+
+	alpha := strings.Join(TokenizeSearchText("alpha"), " ")
+	beta := strings.Join(TokenizeSearchText("beta"), " ")
+
+	// search started before the change
+	_, generation, _ := idx.cached(alpha)
+	stale := idx.search(strings.Fields(alpha))
+
+	idx.Set(1, "beta")
+	idx.storeCache(alpha, stale, generation)
+
+	if ids := idx.Search("alpha", 0); len(ids) != 0 {
+		t.Fatalf("search after the change = %v, want no results", ids)
+	}
+
+	_, generation, _ = idx.cached(beta)
+	idx.storeCache(beta, []uint64{1}, generation)
+
+	if ids, _, ok := idx.cached(beta); !ok || len(ids) != 1 {
+		t.Fatalf("search without changes should be cached, got %v %v", ids, ok)
+	}
+}
+
+func TestLocalSearchIndexSearchCacheIsCleared(t *testing.T) {
+	idx := NewLocalSearchIndex()
+	idx.Set(1, "Receita de bolo")
+
+	if ids := idx.Search("bolo", 0); len(ids) != 1 {
+		t.Fatalf("search = %v, want [1]", ids)
+	}
+
+	idx.Set(2, "Bolo de cenoura")
+	if ids := idx.Search("bolo", 0); len(ids) != 2 {
+		t.Fatalf("search after set = %v, want 2 ids", ids)
+	}
+
+	idx.Remove(1)
+	if ids := idx.Search("bolo", 0); len(ids) != 1 || ids[0] != 2 {
+		t.Fatalf("search after remove = %v, want [2]", ids)
+	}
+}
+
+func TestLocalSearchFilterMaxIds(t *testing.T) {
+	db := newTestDB(t)
+
+	tests := []struct {
+		name  string
+		count int
+		want  int
+	}{
+		{"empty", 0, 0},
+		{"small list", 3, 3},
+		{"over the max", localSearchMaxFiltered + 50, localSearchMaxFiltered},
+	}
+
+	for _, tt := range tests {
+		ids := make([]uint64, tt.count)
+		for i := range ids {
+			ids[i] = uint64(i + 1)
+		}
+
+		stmt := localSearchFilter(db.Session(&gorm.Session{DryRun: true}).Model(&BlogPostModel{}), "blog_posts.id", ids).
+			Find(&[]BlogPostModel{}).Statement
+
+		sql := stmt.SQL.String()
+		if tt.count == 0 {
+			if !strings.Contains(sql, "1 = 0") {
+				t.Errorf("%s: sql %q should not match any record", tt.name, sql)
+			}
+			continue
+		}
+
+		in := sql[strings.Index(sql, "IN (")+4:]
+		in = in[:strings.Index(in, ")")]
+		if got := len(strings.Split(in, ",")); got != tt.want {
+			t.Errorf("%s: filter with %d ids, want %d", tt.name, got, tt.want)
+		}
+	}
+}
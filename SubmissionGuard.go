@@ -0,0 +1,319 @@
+package blog
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-catupiry/catu/configuration"
+	"github.com/sirupsen/logrus"
+)
+
+// Submission guard spam reasons
+const (
+	SubmissionReasonHoneypot     = "honeypot"
+	SubmissionReasonTooFast      = "too-fast"
+	SubmissionReasonInvalidToken = "invalid-token"
+	SubmissionReasonLinks        = "too-many-links"
+	SubmissionReasonBlocklist    = "blocklist"
+)
+
+var submissionLinkRegex = regexp.MustCompile(`(?i)(https?://|www\.|<a\s)`)
+
+// SubmissionGuardCfg - Submission guard settings, zero values disable the check
+type SubmissionGuardCfg struct {
+	// max submissions per IP in the Window
+	IPLimit int
+	// max submissions per authenticated user in the Window
+	UserLimit int
+	Window    time.Duration
+	// min time between the form token creation and the submission
+	MinSubmitTime time.Duration
+	// max form token age, older tokens are invalid
+	MaxTokenAge time.Duration
+	// form token signature key, a random key is used if empty
+	Secret []byte
+	// max links in the submission texts
+	MaxLinks int
+	// case insensitive words or phrases
+	BlocklistWords []string
+	// regular expressions
+	BlocklistPatterns []string
+	// clock, defaults to time.Now
+	Now func() time.Time
+}
+
+// Submission - Public form submission data checked by the SubmissionGuard
+type Submission struct {
+	IP     string
+	UserID *uint64
+	// honeypot field value, hidden in the form and must be empty
+	Honeypot string
+	// token from NewFormToken, required if the min submit time is set
+	FormToken string
+	Texts     []string
+}
+
+// SubmissionCheckResult - RateLimited submissions should be rejected and Spam submissions flagged
+type SubmissionCheckResult struct {
+	RateLimited bool
+	Spam        bool
+	Reasons     []string
+}
+
+// SubmissionGuard - In memory spam and flood protection for public forms like blog comments
+type SubmissionGuard struct {
+	cfg         SubmissionGuardCfg
+	words       []string
+	patterns    []*regexp.Regexp
+	mu          sync.Mutex
+	hits        map[string][]time.Time
+	lastCleanup time.Time
+}
+
+func NewSubmissionGuard(cfg SubmissionGuardCfg) (*SubmissionGuard, error) {
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+
+	if len(cfg.Secret) == 0 {
+		cfg.Secret = make([]byte, 32)
+		_, err := rand.Read(cfg.Secret)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	g := SubmissionGuard{
+		cfg:  cfg,
+		hits: map[string][]time.Time{},
+	}
+
+	for _, w := range cfg.BlocklistWords {
+		w = strings.ToLower(strings.TrimSpace(w))
+		if w != "" {
+			g.words = append(g.words, w)
+		}
+	}
+
+	for _, p := range cfg.BlocklistPatterns {
+		if strings.TrimSpace(p) == "" {
+			continue
+		}
+
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, err
+		}
+
+		g.patterns = append(g.patterns, re)
+	}
+
+	return &g, nil
+}
+
+// NewSubmissionGuardFromConfiguration - Submission guard for blog comments, settings:
+// BLOG_COMMENT_RATE_LIMIT_IP (default 5), BLOG_COMMENT_RATE_LIMIT_USER (default 10), BLOG_COMMENT_RATE_LIMIT_WINDOW (default 10m),
+// BLOG_COMMENT_MIN_SUBMIT_TIME (default 3s), BLOG_COMMENT_FORM_TOKEN_MAX_AGE (default 24h), BLOG_COMMENT_FORM_SECRET (form token key, random if empty), BLOG_COMMENT_MAX_LINKS (default 2),
+// BLOG_COMMENT_BLOCKLIST (comma separated) and BLOG_COMMENT_BLOCKLIST_PATTERNS (regular expressions, one per line)
+func NewSubmissionGuardFromConfiguration(c configuration.ConfigurationInterface) *SubmissionGuard {
+	cfg := SubmissionGuardCfg{}
+
+	cfg.IPLimit, _ = strconv.Atoi(c.GetF("BLOG_COMMENT_RATE_LIMIT_IP", "5"))
+	cfg.UserLimit, _ = strconv.Atoi(c.GetF("BLOG_COMMENT_RATE_LIMIT_USER", "10"))
+	cfg.Window, _ = time.ParseDuration(c.GetF("BLOG_COMMENT_RATE_LIMIT_WINDOW", "10m"))
+	cfg.MinSubmitTime, _ = time.ParseDuration(c.GetF("BLOG_COMMENT_MIN_SUBMIT_TIME", "3s"))
+	cfg.MaxTokenAge, _ = time.ParseDuration(c.GetF("BLOG_COMMENT_FORM_TOKEN_MAX_AGE", "24h"))
+	cfg.MaxLinks, _ = strconv.Atoi(c.GetF("BLOG_COMMENT_MAX_LINKS", "2"))
+	cfg.Secret = []byte(c.Get("BLOG_COMMENT_FORM_SECRET"))
+
+	if words := c.Get("BLOG_COMMENT_BLOCKLIST"); words != "" {
+		cfg.BlocklistWords = strings.Split(words, ",")
+	}
+
+	if patterns := c.Get("BLOG_COMMENT_BLOCKLIST_PATTERNS"); patterns != "" {
+		cfg.BlocklistPatterns = strings.Split(patterns, "\n")
+	}
+
+	g, err := NewSubmissionGuard(cfg)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"error": err,
+		}).Error("NewSubmissionGuardFromConfiguration invalid blocklist pattern, patterns disabled")
+
+		cfg.BlocklistPatterns = nil
+		g, _ = NewSubmissionGuard(cfg)
+	}
+
+	return g
+}
+
+// NewFormToken - Signed token with the current time, sent with the form to check the min submit time
+func (g *SubmissionGuard) NewFormToken() string {
+	issuedAt := strconv.FormatInt(g.cfg.Now().UnixMilli(), 10)
+
+	return issuedAt + "." + g.signFormToken(issuedAt)
+}
+
+// parseFormToken - Token creation time, false if the token is empty or the signature don't match
+func (g *SubmissionGuard) parseFormToken(token string) (time.Time, bool) {
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 {
+		return time.Time{}, false
+	}
+
+	if !hmac.Equal([]byte(parts[1]), []byte(g.signFormToken(parts[0]))) {
+		return time.Time{}, false
+	}
+
+	ms, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return time.UnixMilli(ms), true
+}
+
+func (g *SubmissionGuard) signFormToken(issuedAt string) string {
+	mac := hmac.New(sha256.New, g.cfg.Secret)
+	mac.Write([]byte(issuedAt))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Check - Check the submission. Allowed submissions are counted in the rate limits
+func (g *SubmissionGuard) Check(s *Submission) *SubmissionCheckResult {
+	now := g.cfg.Now()
+	result := SubmissionCheckResult{}
+
+	if g.isRateLimited(s, now) {
+		result.RateLimited = true
+		return &result
+	}
+
+	if strings.TrimSpace(s.Honeypot) != "" {
+		result.Reasons = append(result.Reasons, SubmissionReasonHoneypot)
+	}
+
+	if g.cfg.MinSubmitTime > 0 {
+		startedAt, ok := g.parseFormToken(s.FormToken)
+		switch {
+		case !ok, g.cfg.MaxTokenAge > 0 && now.Sub(startedAt) > g.cfg.MaxTokenAge:
+			result.Reasons = append(result.Reasons, SubmissionReasonInvalidToken)
+		case now.Sub(startedAt) < g.cfg.MinSubmitTime:
+			result.Reasons = append(result.Reasons, SubmissionReasonTooFast)
+		}
+	}
+
+	if g.cfg.MaxLinks > 0 && g.countLinks(s.Texts) > g.cfg.MaxLinks {
+		result.Reasons = append(result.Reasons, SubmissionReasonLinks)
+	}
+
+	if g.matchBlocklist(s.Texts) {
+		result.Reasons = append(result.Reasons, SubmissionReasonBlocklist)
+	}
+
+	result.Spam = len(result.Reasons) > 0
+
+	return &result
+}
+
+// isRateLimited - Check the IP and user limits and register the hit if allowed
+func (g *SubmissionGuard) isRateLimited(s *Submission, now time.Time) bool {
+	if g.cfg.Window <= 0 {
+		return false
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.cleanup(now)
+
+	keys := []string{}
+	limits := []int{}
+
+	if s.IP != "" && g.cfg.IPLimit > 0 {
+		keys = append(keys, "ip:"+s.IP)
+		limits = append(limits, g.cfg.IPLimit)
+	}
+
+	if s.UserID != nil && g.cfg.UserLimit > 0 {
+		keys = append(keys, "user:"+strconv.FormatUint(*s.UserID, 10))
+		limits = append(limits, g.cfg.UserLimit)
+	}
+
+	for i, key := range keys {
+		g.hits[key] = g.recentHits(key, now)
+		if len(g.hits[key]) >= limits[i] {
+			return true
+		}
+	}
+
+	for _, key := range keys {
+		g.hits[key] = append(g.hits[key], now)
+	}
+
+	return false
+}
+
+func (g *SubmissionGuard) recentHits(key string, now time.Time) []time.Time {
+	hits := g.hits[key]
+	start := now.Add(-g.cfg.Window)
+
+	i := 0
+	for i < len(hits) && !hits[i].After(start) {
+		i++
+	}
+
+	return hits[i:]
+}
+
+// cleanup - Remove the keys without hits in the window, runs once per window
+func (g *SubmissionGuard) cleanup(now time.Time) {
+	if now.Sub(g.lastCleanup) < g.cfg.Window {
+		return
+	}
+
+	g.lastCleanup = now
+
+	for key := range g.hits {
+		if len(g.recentHits(key, now)) == 0 {
+			delete(g.hits, key)
+		}
+	}
+}
+
+func (g *SubmissionGuard) countLinks(texts []string) int {
+	count := 0
+	for _, t := range texts {
+		count += len(submissionLinkRegex.FindAllStringIndex(t, -1))
+	}
+
+	return count
+}
+
+func (g *SubmissionGuard) matchBlocklist(texts []string) bool {
+	for _, t := range texts {
+		lower := strings.ToLower(t)
+
+		for _, w := range g.words {
+			if strings.Contains(lower, w) {
+				return true
+			}
+		}
+
+		for _, re := range g.patterns {
+			if re.MatchString(t) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
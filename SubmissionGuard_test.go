@@ -0,0 +1,223 @@
+package blog
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type guardClock struct {
+	now time.Time
+}
+
+func (c *guardClock) Now() time.Time {
+	return c.now
+}
+
+func newTestSubmissionGuard(t *testing.T, cfg SubmissionGuardCfg) (*SubmissionGuard, *guardClock) {
+	clock := guardClock{now: time.Date(2022, 9, 14, 10, 0, 0, 0, time.UTC)}
+	cfg.Now = clock.Now
+	cfg.Secret = []byte("test-secret")
+
+	g, err := NewSubmissionGuard(cfg)
+	if err != nil {
+		t.Fatalf("NewSubmissionGuard error: %v", err)
+	}
+
+	return g, &clock
+}
+
+func hasReason(result *SubmissionCheckResult, reason string) bool {
+	for _, r := range result.Reasons {
+		if r == reason {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestSubmissionGuardIPRateLimit(t *testing.T) {
+	g, clock := newTestSubmissionGuard(t, SubmissionGuardCfg{IPLimit: 2, Window: time.Minute})
+
+	for i := 0; i < 2; i++ {
+		if g.Check(&Submission{IP: "10.0.0.1"}).RateLimited {
+			t.Fatalf("submission %d should be allowed", i+1)
+		}
+	}
+
+	if !g.Check(&Submission{IP: "10.0.0.1"}).RateLimited {
+		t.Fatal("third submission in the window should be rate limited")
+	}
+
+	if g.Check(&Submission{IP: "10.0.0.2"}).RateLimited {
+		t.Fatal("other ips should not be rate limited")
+	}
+
+	clock.now = clock.now.Add(time.Minute + time.Second)
+
+	if g.Check(&Submission{IP: "10.0.0.1"}).RateLimited {
+		t.Fatal("submission after the window should be allowed")
+	}
+}
+
+func TestSubmissionGuardUserRateLimit(t *testing.T) {
+	g, _ := newTestSubmissionGuard(t, SubmissionGuardCfg{UserLimit: 1, Window: time.Minute})
+
+	userID := uint64(7)
+
+	if g.Check(&Submission{IP: "10.0.0.1", UserID: &userID}).RateLimited {
+		t.Fatal("first submission should be allowed")
+	}
+
+	if !g.Check(&Submission{IP: "10.0.0.2", UserID: &userID}).RateLimited {
+		t.Fatal("user limit should apply from other ips")
+	}
+}
+
+func TestSubmissionGuardHoneypot(t *testing.T) {
+	g, _ := newTestSubmissionGuard(t, SubmissionGuardCfg{})
+
+	result := g.Check(&Submission{Honeypot: "http://spam.example.com"})
+	if !result.Spam || !hasReason(result, SubmissionReasonHoneypot) {
+		t.Fatalf("filled honeypot should be spam, got %+v", result)
+	}
+
+	if result := g.Check(&Submission{Honeypot: " "}); result.Spam {
+		t.Fatalf("blank honeypot should not be spam, got %+v", result)
+	}
+}
+
+func TestSubmissionGuardLinks(t *testing.T) {
+	g, _ := newTestSubmissionGuard(t, SubmissionGuardCfg{MaxLinks: 2})
+
+	result := g.Check(&Submission{Texts: []string{"see https://a.example.com and www.b.example.com"}})
+	if result.Spam {
+		t.Fatalf("two links should be allowed, got %+v", result)
+	}
+
+	result = g.Check(&Submission{Texts: []string{"https://a.example.com", `<a href="/x">x</a> http://c.example.com`}})
+	if !result.Spam || !hasReason(result, SubmissionReasonLinks) {
+		t.Fatalf("three links should be spam, got %+v", result)
+	}
+}
+
+func TestSubmissionGuardBlocklist(t *testing.T) {
+	g, _ := newTestSubmissionGuard(t, SubmissionGuardCfg{
+		BlocklistWords:    []string{" Casino ", ""},
+		BlocklistPatterns: []string{`\bv[i1]agra\b`, ""},
+	})
+
+	tests := []struct {
+		text string
+		spam bool
+	}{
+		{"Online CASINO bonus", true},
+		{"buy v1agra now", true},
+		{"nice post, thanks", false},
+	}
+
+	for _, tt := range tests {
+		result := g.Check(&Submission{Texts: []string{tt.text}})
+		if result.Spam != tt.spam {
+			t.Errorf("%q spam = %v, want %v", tt.text, result.Spam, tt.spam)
+		}
+		if tt.spam && !hasReason(result, SubmissionReasonBlocklist) {
+			t.Errorf("%q should have the blocklist reason, got %v", tt.text, result.Reasons)
+		}
+	}
+}
+
+func TestSubmissionGuardInvalidPattern(t *testing.T) {
+	_, err := NewSubmissionGuard(SubmissionGuardCfg{BlocklistPatterns: []string{"("}})
+	if err == nil {
+		t.Fatal("invalid pattern should return error")
+	}
+}
+
+func TestSubmissionGuardFormToken(t *testing.T) {
+	g, clock := newTestSubmissionGuard(t, SubmissionGuardCfg{MinSubmitTime: 3 * time.Second, MaxTokenAge: time.Hour})
+
+	token := g.NewFormToken()
+
+	result := g.Check(&Submission{FormToken: token})
+	if !result.Spam || !hasReason(result, SubmissionReasonTooFast) {
+		t.Fatalf("submission right after the token should be spam, got %+v", result)
+	}
+
+	clock.now = clock.now.Add(5 * time.Second)
+
+	if result := g.Check(&Submission{FormToken: token}); result.Spam {
+		t.Fatalf("submission after the min time should not be spam, got %+v", result)
+	}
+
+	forged := strconv.FormatInt(clock.now.Add(-time.Minute).UnixMilli(), 10) + token[strings.Index(token, "."):]
+
+	other, _ := newTestSubmissionGuard(t, SubmissionGuardCfg{MinSubmitTime: 3 * time.Second})
+	other.cfg.Secret = []byte("other-secret")
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"missing", ""},
+		{"garbage", "not-a-token"},
+		{"forged timestamp", forged},
+		{"other secret", other.NewFormToken()},
+	}
+
+	for _, tt := range tests {
+		result := g.Check(&Submission{FormToken: tt.token})
+		if !result.Spam || !hasReason(result, SubmissionReasonInvalidToken) {
+			t.Errorf("%s token should be spam with the invalid token reason, got %+v", tt.name, result)
+		}
+	}
+}
+
+func TestSubmissionGuardFormTokenMaxAge(t *testing.T) {
+	g, clock := newTestSubmissionGuard(t, SubmissionGuardCfg{MinSubmitTime: 3 * time.Second, MaxTokenAge: time.Hour})
+
+	token := g.NewFormToken()
+
+	clock.now = clock.now.Add(59 * time.Minute)
+	if result := g.Check(&Submission{FormToken: token}); result.Spam {
+		t.Fatalf("token inside the max age should be valid, got %+v", result)
+	}
+
+	clock.now = clock.now.Add(2 * time.Minute)
+	result := g.Check(&Submission{FormToken: token})
+	if !result.Spam || !hasReason(result, SubmissionReasonInvalidToken) {
+		t.Fatalf("expired token should be spam, got %+v", result)
+	}
+}
+
+func TestSubmissionGuardWithoutMinSubmitTime(t *testing.T) {
+	g, _ := newTestSubmissionGuard(t, SubmissionGuardCfg{})
+
+	if result := g.Check(&Submission{}); result.Spam {
+		t.Fatalf("token is not required if the min submit time is disabled, got %+v", result)
+	}
+}
+
+func TestSubmissionGuardValidSubmission(t *testing.T) {
+	g, clock := newTestSubmissionGuard(t, SubmissionGuardCfg{
+		IPLimit:        5,
+		Window:         time.Minute,
+		MinSubmitTime:  time.Second,
+		MaxLinks:       1,
+		BlocklistWords: []string{"casino"},
+	})
+
+	token := g.NewFormToken()
+	clock.now = clock.now.Add(10 * time.Second)
+
+	result := g.Check(&Submission{
+		IP:        "10.0.0.1",
+		FormToken: token,
+		Texts:     []string{"Maria", "Great post, see https://example.com"},
+	})
+	if result.RateLimited || result.Spam {
+		t.Fatalf("valid submission should be allowed, got %+v", result)
+	}
+}
@@ -0,0 +1,65 @@
+package blog
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/go-catupiry/catu"
+	"github.com/go-catupiry/files"
+	"github.com/go-catupiry/tags"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var testAppOnce sync.Once
+var testApp catu.App
+
+// newTestDB - App with a new in memory sqlite database and the blog tables
+func newTestDB(t *testing.T) *gorm.DB {
+	testAppOnce.Do(func() {
+		testApp = catu.Init(&catu.AppOptions{})
+	})
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("error on open database: %v", err)
+	}
+
+	// one connection, each new in memory connection is a empty database
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("error on get database: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	testApp.SetDB(db)
+
+	// the tags field configurations keep the database connection
+	tagsFieldCfg = tags.NewTagFieldConfiguration(blogTagsVocabulary, "blog", "tags")
+	blogPostTagsFieldCfg = tags.NewTagFieldConfiguration(blogTagsVocabulary, "blog-post", "tags")
+	logoFieldCfg = files.NewImageFieldConfiguration("blog", "logo")
+	featuredImageFieldCfg = files.NewImageFieldConfiguration("blog-post", "featuredImage")
+	galleryFieldCfg = files.NewImageFieldConfiguration("blog-post", "gallery")
+	galleryFieldCfg.SetFormFieldMultiple(true)
+	attachmentsFieldCfg = files.NewFileFieldConfiguration("blog-post", "attachments")
+
+	// CreateTable don't create the related tables, sqlite index names are unique in the database
+	err = db.Migrator().CreateTable(&BlogPostModel{}, &BlogPostRevisionModel{}, &BlogPostMediaModel{}, &BlogPostAuthorModel{})
+	if err != nil {
+		t.Fatalf("error on migrate blog tables: %v", err)
+	}
+
+	// the images creatorId index name is already used by the blog posts table
+	err = db.Exec("CREATE TABLE images (id integer PRIMARY KEY, name varchar(255), urls blob NOT NULL DEFAULT '', extraData blob, active tinyint(1) DEFAULT 1, isLocalStorage tinyint(1) DEFAULT 1, label varchar(255), description text, size integer, encoding varchar(255), originalname varchar(255), mime varchar(255), extension varchar(255), storageName varchar(255), createdAt datetime, updatedAt datetime, creatorId integer)").Error
+	if err != nil {
+		t.Fatalf("error on create images table: %v", err)
+	}
+
+	err = db.Migrator().CreateTable(&files.ImageAssocsModel{}, &tags.TermModel{}, &tags.ModelstermsModel{})
+	if err != nil {
+		t.Fatalf("error on migrate image assocs table: %v", err)
+	}
+
+	return db
+}
@@ -0,0 +1,243 @@
+package blog
+
+import (
+	"strconv"
+	"strings"
+)
+
+// number of unchanged lines shown around each change
+const diffContextLines = 3
+
+type diffOp struct {
+	Kind byte // ' ' unchanged, '-' removed, '+' added
+	Line string
+	// 0-based position in the old and new texts
+	A int
+	B int
+}
+
+// unifiedDiff - Line based unified diff between two texts, returns a empty string if both are equal
+func unifiedDiff(fromName, toName, a, b string) string {
+	if a == b {
+		return ""
+	}
+
+	ops := diffLines(splitDiffLines(a), splitDiffLines(b))
+
+	var out strings.Builder
+	out.WriteString("--- " + fromName + "\n")
+	out.WriteString("+++ " + toName + "\n")
+
+	prevEnd := 0
+	k := 0
+	for k < len(ops) {
+		// find the next change
+		for k < len(ops) && ops[k].Kind == ' ' {
+			k++
+		}
+		if k >= len(ops) {
+			break
+		}
+
+		start := k - diffContextLines
+		if start < prevEnd {
+			start = prevEnd
+		}
+
+		// extend the hunk until we find a unchanged block bigger than the context of two hunks
+		end := k
+		equalRun := 0
+		for end < len(ops) {
+			if ops[end].Kind == ' ' {
+				equalRun++
+				if equalRun > 2*diffContextLines {
+					break
+				}
+			} else {
+				equalRun = 0
+			}
+			end++
+		}
+
+		trailing := 0
+		for i := end - 1; i >= start && ops[i].Kind == ' '; i-- {
+			trailing++
+		}
+		if trailing > diffContextLines {
+			end = end - trailing + diffContextLines
+		}
+
+		writeDiffHunk(&out, ops[start:end])
+
+		prevEnd = end
+		k = end
+	}
+
+	return out.String()
+}
+
+func writeDiffHunk(out *strings.Builder, ops []diffOp) {
+	aCount, bCount := 0, 0
+	for i := range ops {
+		if ops[i].Kind != '+' {
+			aCount++
+		}
+		if ops[i].Kind != '-' {
+			bCount++
+		}
+	}
+
+	aStart := ops[0].A + 1
+	if aCount == 0 {
+		aStart = ops[0].A
+	}
+
+	bStart := ops[0].B + 1
+	if bCount == 0 {
+		bStart = ops[0].B
+	}
+
+	out.WriteString("@@ -" + strconv.Itoa(aStart) + "," + strconv.Itoa(aCount) +
+		" +" + strconv.Itoa(bStart) + "," + strconv.Itoa(bCount) + " @@\n")
+
+	for i := range ops {
+		out.WriteByte(ops[i].Kind)
+		out.WriteString(ops[i].Line)
+		out.WriteString("\n")
+	}
+}
+
+// diffLines - Longest common subsequence diff between two line lists. Uses the Hirschberg algorithm,
+// memory is linear in the number of lines
+func diffLines(a, b []string) []diffOp {
+	ops := []diffOp{}
+
+	// the common prefix and suffix are unchanged
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, diffOp{Kind: ' ', Line: a[i], A: i, B: i})
+	}
+
+	ops = diffLinesRange(ops, a, b, prefix, len(a)-suffix, prefix, len(b)-suffix)
+
+	for k := suffix; k > 0; k-- {
+		i, j := len(a)-k, len(b)-k
+		ops = append(ops, diffOp{Kind: ' ', Line: a[i], A: i, B: j})
+	}
+
+	return ops
+}
+
+// diffLinesRange - Append the diff of a[aStart:aEnd] and b[bStart:bEnd], splitting a in the middle
+func diffLinesRange(ops []diffOp, a, b []string, aStart, aEnd, bStart, bEnd int) []diffOp {
+	switch {
+	case aStart == aEnd:
+		for j := bStart; j < bEnd; j++ {
+			ops = append(ops, diffOp{Kind: '+', Line: b[j], A: aStart, B: j})
+		}
+		return ops
+	case bStart == bEnd:
+		for i := aStart; i < aEnd; i++ {
+			ops = append(ops, diffOp{Kind: '-', Line: a[i], A: i, B: bStart})
+		}
+		return ops
+	case aEnd-aStart == 1:
+		match := -1
+		for j := bStart; j < bEnd; j++ {
+			if a[aStart] == b[j] {
+				match = j
+				break
+			}
+		}
+
+		if match == -1 {
+			ops = append(ops, diffOp{Kind: '-', Line: a[aStart], A: aStart, B: bStart})
+			return diffLinesRange(ops, a, b, aEnd, aEnd, bStart, bEnd)
+		}
+
+		ops = diffLinesRange(ops, a, b, aStart, aStart, bStart, match)
+		ops = append(ops, diffOp{Kind: ' ', Line: a[aStart], A: aStart, B: match})
+		return diffLinesRange(ops, a, b, aEnd, aEnd, match+1, bEnd)
+	}
+
+	aMid := (aStart + aEnd) / 2
+
+	forward := lcsLengthsForward(a[aStart:aMid], b[bStart:bEnd])
+	backward := lcsLengthsBackward(a[aMid:aEnd], b[bStart:bEnd])
+
+	// split b where the LCS of the two halves is the longest
+	split, best := 0, -1
+	for k := range forward {
+		if forward[k]+backward[k] > best {
+			best = forward[k] + backward[k]
+			split = k
+		}
+	}
+
+	ops = diffLinesRange(ops, a, b, aStart, aMid, bStart, bStart+split)
+	return diffLinesRange(ops, a, b, aMid, aEnd, bStart+split, bEnd)
+}
+
+// lcsLengthsForward - LCS length of a and b[:k] for each k
+func lcsLengthsForward(a, b []string) []int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+
+	for i := range a {
+		cur[0] = 0
+		for j := 1; j <= len(b); j++ {
+			if a[i] == b[j-1] {
+				cur[j] = prev[j-1] + 1
+			} else if prev[j] >= cur[j-1] {
+				cur[j] = prev[j]
+			} else {
+				cur[j] = cur[j-1]
+			}
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev
+}
+
+// lcsLengthsBackward - LCS length of a and b[k:] for each k
+func lcsLengthsBackward(a, b []string) []int {
+	m := len(b)
+	prev := make([]int, m+1)
+	cur := make([]int, m+1)
+
+	for i := len(a) - 1; i >= 0; i-- {
+		cur[m] = 0
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				cur[j] = prev[j+1] + 1
+			} else if prev[j] >= cur[j+1] {
+				cur[j] = prev[j]
+			} else {
+				cur[j] = cur[j+1]
+			}
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev
+}
+
+func splitDiffLines(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+
+	return strings.Split(s, "\n")
+}
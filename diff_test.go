@@ -0,0 +1,95 @@
+package blog
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	numbers := make([]string, 20)
+	for i := range numbers {
+		numbers[i] = strconv.Itoa(i + 1)
+	}
+	changed := append([]string{}, numbers...)
+	changed[1] = "two"
+	changed[17] = "eighteen"
+
+	header := "--- from\n+++ to\n"
+
+	tests := []struct {
+		name string
+		a    string
+		b    string
+		want string
+	}{
+		{"empty", "", "", ""},
+		{"identical", "a\nb\nc", "a\nb\nc", ""},
+		{"insert in empty text", "", "a\nb", header + "@@ -0,0 +1,2 @@\n+a\n+b\n"},
+		{"insert only", "a\nb\nc", "a\nb\nx\nc", header + "@@ -1,3 +1,4 @@\n a\n b\n+x\n c\n"},
+		{"delete only", "a\nb\nc", "a\nc", header + "@@ -1,3 +1,2 @@\n a\n-b\n c\n"},
+		{"delete all", "a\nb", "", header + "@@ -1,2 +0,0 @@\n-a\n-b\n"},
+		{"mixed", "a\nb\nc\nd", "a\nx\nc\nd\ne", header + "@@ -1,4 +1,5 @@\n a\n-b\n+x\n c\n d\n+e\n"},
+		{
+			"two hunks",
+			strings.Join(numbers, "\n"),
+			strings.Join(changed, "\n"),
+			header +
+				"@@ -1,5 +1,5 @@\n 1\n-2\n+two\n 3\n 4\n 5\n" +
+				"@@ -15,6 +15,6 @@\n 15\n 16\n 17\n-18\n+eighteen\n 19\n 20\n",
+		},
+	}
+
+	for _, tt := range tests {
+		if got := unifiedDiff("from", "to", tt.a, tt.b); got != tt.want {
+			t.Errorf("%s: unifiedDiff =\n%s\nwant\n%s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a    []string
+		b    []string
+		want string
+	}{
+		{"empty", []string{}, []string{}, ""},
+		{"identical", []string{"a", "b"}, []string{"a", "b"}, " a b"},
+		{"insert only", []string{"a"}, []string{"x", "a", "y"}, "+x a+y"},
+		{"delete only", []string{"x", "a", "y"}, []string{"a"}, "-x a-y"},
+		{"mixed", []string{"a", "b", "c", "d"}, []string{"b", "x", "d", "e"}, "-a b-c+x d+e"},
+	}
+
+	for _, tt := range tests {
+		ops := diffLines(tt.a, tt.b)
+
+		var got strings.Builder
+		aLines, bLines := 0, 0
+		for _, op := range ops {
+			got.WriteByte(op.Kind)
+			got.WriteString(op.Line)
+
+			if op.Kind != '+' {
+				if op.A >= len(tt.a) || tt.a[op.A] != op.Line {
+					t.Errorf("%s: op %q has wrong old position %d", tt.name, op.Line, op.A)
+				}
+				aLines++
+			}
+			if op.Kind != '-' {
+				if op.B >= len(tt.b) || tt.b[op.B] != op.Line {
+					t.Errorf("%s: op %q has wrong new position %d", tt.name, op.Line, op.B)
+				}
+				bLines++
+			}
+		}
+
+		if got.String() != tt.want {
+			t.Errorf("%s: diffLines = %q, want %q", tt.name, got.String(), tt.want)
+		}
+
+		if aLines != len(tt.a) || bLines != len(tt.b) {
+			t.Errorf("%s: diff covers %d old and %d new lines, want %d and %d", tt.name, aLines, bLines, len(tt.a), len(tt.b))
+		}
+	}
+}